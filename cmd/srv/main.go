@@ -1,14 +1,25 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"srv.exe.dev/srv"
 )
 
-var flagListenAddr = flag.String("listen", ":8000", "address to listen on")
+var (
+	flagListenAddr   = flag.String("listen", ":8000", "address to listen on")
+	flagDrainTimeout = flag.Duration("drain-timeout", 60*time.Second, "how long to let games finish on shutdown")
+	flagAdmins       = flag.String("admins", "", "comma-separated exe.dev user IDs or emails allowed to use /admin")
+	flagProxies      = flag.String("trusted-proxies", "", "comma-separated proxy IPs or CIDRs whose X-Forwarded-For is trusted (default loopback)")
+)
 
 func main() {
 	if err := run(); err != nil {
@@ -26,5 +37,34 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("create server: %w", err)
 	}
-	return server.Serve(*flagListenAddr)
+	if *flagAdmins != "" {
+		server.SetAdmins(strings.Split(*flagAdmins, ","))
+	}
+	if *flagProxies != "" {
+		if err := server.SetTrustedProxies(strings.Split(*flagProxies, ",")); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.Serve(*flagListenAddr)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	slog.Info("shutdown signal received", "drainTimeout", *flagDrainTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *flagDrainTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,19 @@
+-- Aggregate results for multi-round (best of N) matches
+CREATE TABLE IF NOT EXISTS match_results (
+    id TEXT PRIMARY KEY,
+    room_name TEXT NOT NULL,
+    best_of INTEGER NOT NULL DEFAULT 1,
+    winner TEXT NOT NULL DEFAULT '',
+    wins_json TEXT NOT NULL DEFAULT '{}',
+    rounds_json TEXT NOT NULL DEFAULT '[]',
+    finished INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Each round's game result points back to its match
+ALTER TABLE game_results ADD COLUMN match_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE game_results ADD COLUMN round INTEGER NOT NULL DEFAULT 0;
+
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (003, '003-match-results');
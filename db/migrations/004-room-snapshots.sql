@@ -0,0 +1,10 @@
+-- Periodic snapshots of live rooms, restored on startup
+CREATE TABLE IF NOT EXISTS room_snapshots (
+    room_id TEXT PRIMARY KEY,
+    status TEXT NOT NULL DEFAULT '',
+    snapshot_json TEXT NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (004, '004-room-snapshots');
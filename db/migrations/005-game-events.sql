@@ -0,0 +1,13 @@
+-- Append-only event log per saved game, one row per event
+CREATE TABLE IF NOT EXISTS game_events (
+    result_id TEXT NOT NULL,
+    seq INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    event_json TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (result_id, seq)
+);
+
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (005, '005-game-events');
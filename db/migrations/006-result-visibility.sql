@@ -0,0 +1,11 @@
+-- Owner-controlled visibility: public, unlisted (link only) or private
+ALTER TABLE game_results ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public';
+
+-- Server-generated keys, e.g. for signing result owner tokens
+CREATE TABLE IF NOT EXISTS server_secrets (
+    name TEXT PRIMARY KEY,
+    value BLOB NOT NULL
+);
+
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (006, '006-result-visibility');
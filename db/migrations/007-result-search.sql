@@ -0,0 +1,24 @@
+-- Full-text search over the words of saved games. The trigram tokenizer
+-- matches any substring of three or more characters, which suits Japanese.
+CREATE VIRTUAL TABLE IF NOT EXISTS game_results_fts USING fts5(
+    result_id UNINDEXED,
+    words,
+    readings,
+    tokenize = 'trigram'
+);
+
+-- Index existing results. Readings are filled in by the server for new
+-- results; older ones fall back to the words as played.
+INSERT INTO game_results_fts (result_id, words, readings)
+SELECT id, words, words
+FROM (SELECT gr.id,
+             (SELECT COALESCE(group_concat(json_extract(h.value, '$.word'), ' '), '')
+              FROM json_each(gr.history_json) h) AS words
+      FROM game_results gr
+      WHERE json_valid(gr.history_json));
+
+CREATE INDEX IF NOT EXISTS idx_game_results_visibility_created
+    ON game_results (visibility, created_at);
+
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (007, '007-result-search');
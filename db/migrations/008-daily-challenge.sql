@@ -0,0 +1,17 @@
+-- Best clear per player of each daily challenge puzzle. Fewer words, then a
+-- shorter time, ranks higher.
+CREATE TABLE IF NOT EXISTS daily_results (
+    date TEXT NOT NULL,
+    player TEXT NOT NULL,
+    words INTEGER NOT NULL,
+    duration_ms INTEGER NOT NULL,
+    result_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (date, player)
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_results_rank
+    ON daily_results (date, words, duration_ms);
+
+INSERT OR IGNORE INTO migrations (migration_number, migration_name)
+VALUES (008, '008-daily-challenge');
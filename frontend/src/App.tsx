@@ -2,9 +2,11 @@ import { useCallback, useEffect, useRef, useState } from 'react';
 import { useWebSocket } from './hooks/useWebSocket';
 import { useGameState } from './hooks/useGameState';
 import type { IncomingMessage, OutgoingMessage } from './types/messages';
-import { nextToastId } from './utils/helpers';
+import { nextToastId, saveResultToken } from './utils/helpers';
 import { ToastContainer } from './components/common/Toast';
 import { ThemeSwitcher } from './components/common/ThemeSwitcher';
+import { ShutdownBanner } from './components/common/ShutdownBanner';
+import { NoticeBanner } from './components/common/NoticeBanner';
 import { Lobby } from './components/Lobby';
 import { GameRoom } from './components/Game';
 import { VotePanel } from './components/Vote/VotePanel';
@@ -46,10 +48,23 @@ function App() {
           dispatch({ type: 'WORD_ACCEPTED', msg });
           dispatch({
             type: 'ADD_MESSAGE',
-            text: `${msg.player}さんが正解！「${msg.word}」`,
+            text: `${msg.player}さんが正解！「${msg.word}」` +
+              (msg.responseMs !== undefined ? `（${(msg.responseMs / 1000).toFixed(1)}秒）` : ''),
             msgType: 'success',
           });
           break;
+        case 'genre_changed':
+          dispatch({ type: 'GENRE_CHANGED', genre: msg.genre });
+          break;
+        case 'bomb_exploded':
+          dispatch({ type: 'BOMB_EXPLODED', player: msg.player, currentTurn: msg.currentTurn });
+          break;
+        case 'item_used':
+          dispatch({ type: 'ITEM_USED', msg });
+          break;
+        case 'item_rejected':
+          dispatch({ type: 'ANSWER_REJECTED', message: msg.message });
+          break;
         case 'answer_rejected':
           dispatch({ type: 'ANSWER_REJECTED', message: msg.message });
           break;
@@ -62,6 +77,13 @@ function App() {
             dispatch({ type: 'SET_SHARE_URL', url: `${location.origin}/results/${msg.resultId}` });
           }
           break;
+        case 'rematch_update':
+          dispatch({ type: 'REMATCH_UPDATE', msg });
+          break;
+        case 'result_owner':
+          saveResultToken(msg.resultId, msg.token);
+          dispatch({ type: 'RESULT_OWNER', msg });
+          break;
         case 'vote_request':
           dispatch({ type: 'VOTE_REQUEST', msg });
           setRebuttals([]);
@@ -88,9 +110,25 @@ function App() {
           dispatch({ type: 'TURN_UPDATE', msg });
           break;
         case 'settings_updated':
-          dispatch({ type: 'SETTINGS_UPDATED', settings: msg.settings });
+          dispatch({ type: 'SETTINGS_UPDATED', settings: msg.settings, rules: msg.rules });
           dispatch({ type: 'ADD_MESSAGE', text: '⚙️ ルールが変更されました', msgType: 'info' });
           break;
+        case 'server_shutdown':
+          dispatch({ type: 'SERVER_SHUTDOWN', countdown: msg.countdown, message: msg.message });
+          break;
+        case 'announcement':
+          dispatch({ type: 'NOTICE', message: msg.message });
+          break;
+        case 'kicked':
+        case 'room_closed': {
+          // The server drops the connection next; go back to the lobby.
+          dispatch({ type: 'LEAVE_ROOM' });
+          dispatch({ type: 'NOTICE', message: msg.message });
+          const url = new URL(window.location.href);
+          url.searchParams.delete('room');
+          window.history.replaceState({}, '', url.toString());
+          break;
+        }
         case 'error':
           dispatch({ type: 'ADD_MESSAGE', text: msg.message, msgType: 'error' });
           dispatch({
@@ -145,7 +183,7 @@ function App() {
   const handleSend = useCallback(
     (msg: OutgoingMessage) => {
       // Set name on create/join
-      if (msg.type === 'create_room' || msg.type === 'join') {
+      if (msg.type === 'create_room' || msg.type === 'join' || msg.type === 'start_daily') {
         dispatch({ type: 'SET_NAME', name: msg.name });
       }
       send(msg);
@@ -181,6 +219,14 @@ function App() {
 
       <ThemeSwitcher />
 
+      {state.shutdown && (
+        <ShutdownBanner message={state.shutdown.message} endsAt={state.shutdown.endsAt}
+          onDismiss={() => dispatch({ type: 'DISMISS_SHUTDOWN' })} />
+      )}
+      {state.notice && (
+        <NoticeBanner message={state.notice} onDismiss={() => dispatch({ type: 'DISMISS_NOTICE' })} />
+      )}
+
       {state.screen === 'lobby' && (
         <Lobby state={state} dispatch={dispatch} onSend={handleSend} />
       )}
@@ -208,6 +254,9 @@ function App() {
           onSend={handleSend}
           onBackToLobby={handleBackToLobby}
           lastShareURL={state.lastShareURL}
+          resultOwner={state.resultOwner}
+          match={state.match}
+          rematch={state.rematch}
         />
       )}
     </>
@@ -0,0 +1,50 @@
+import { useState } from 'react';
+import type { ItemType, OutgoingMessage } from '../../types/messages';
+
+export const ITEM_LABELS: Record<ItemType, string> = {
+  pass: '⏭️ パス',
+  reverse: '🔁 順番逆転',
+  force: '🔤 頭文字指定',
+  time: '⏱️ +5秒',
+};
+
+interface Props {
+  items: Partial<Record<ItemType, number>>;
+  isMyTurn: boolean;
+  onSend: (msg: OutgoingMessage) => void;
+}
+
+// ItemBar shows this player's items; they can be used on their turn.
+export function ItemBar({ items, isMyTurn, onSend }: Props) {
+  const [kana, setKana] = useState('');
+  const owned = (Object.keys(ITEM_LABELS) as ItemType[]).filter((item) => (items[item] ?? 0) > 0);
+  if (owned.length === 0) return null;
+
+  const spendItem = (item: ItemType) => {
+    if (item === 'force') {
+      // The server checks the kana against the room's rules.
+      if (!kana.trim()) return;
+      onSend({ type: 'use_item', item, kana: kana.trim() });
+      setKana('');
+      return;
+    }
+    onSend({ type: 'use_item', item });
+  };
+
+  return (
+    <div className="item-bar">
+      {owned.map((item) => (
+        <span key={item} className="item-bar-item">
+          {item === 'force' && (
+            <input type="text" className="item-kana" placeholder="か" value={kana} lang="ja"
+              onChange={(e) => setKana(e.target.value)} disabled={!isMyTurn} />
+          )}
+          <button className="btn btn-outline" onClick={() => spendItem(item)}
+            disabled={!isMyTurn || (item === 'force' && !kana.trim())}>
+            {ITEM_LABELS[item]} ×{items[item]}
+          </button>
+        </span>
+      ))}
+    </div>
+  );
+}
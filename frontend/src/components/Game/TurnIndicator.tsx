@@ -2,17 +2,23 @@ interface Props {
   currentTurn: string;
   myName: string;
   turnOrder: string[];
+  // In a race nobody holds the turn; everyone still alive answers every word.
+  race?: boolean;
+  alive?: boolean;
+  bomb?: boolean;
 }
 
-export function TurnIndicator({ currentTurn, myName, turnOrder }: Props) {
-  const isMyTurn = currentTurn === myName;
+export function TurnIndicator({ currentTurn, myName, turnOrder, race, alive, bomb }: Props) {
+  const isMyTurn = race ? !!alive : currentTurn === myName;
+
+  let text = isMyTurn ? '🎯 あなたの番です！' : `⏳ ${currentTurn}さんの番です`;
+  if (bomb) text = isMyTurn ? '💣 爆弾を持っています！早く答えて渡そう' : `💣 ${currentTurn}さんが爆弾を持っています`;
+  if (race) text = isMyTurn ? '⚡ 早い者勝ち！だれよりも早く答えよう' : '⚡ 早い者勝ちの対戦を観戦中';
 
   return (
     <div className={`turn-indicator ${isMyTurn ? 'my-turn' : 'other-turn'}`}>
-      <span>
-        {isMyTurn ? '🎯 あなたの番です！' : `⏳ ${currentTurn}さんの番です`}
-      </span>
-      {turnOrder.length > 1 && (
+      <span>{text}</span>
+      {!race && turnOrder.length > 1 && (
         <div className="turn-order-list">
           {turnOrder.map((n) => (
             <span key={n} className={`turn-order-item${n === currentTurn ? ' active' : ''}`}>
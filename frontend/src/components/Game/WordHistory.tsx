@@ -2,9 +2,10 @@ import type { HistoryEntry } from '../../types/messages';
 
 interface Props {
   history: HistoryEntry[];
+  showGenre?: boolean;
 }
 
-export function WordHistory({ history }: Props) {
+export function WordHistory({ history, showGenre }: Props) {
   return (
     <div className="history-panel card">
       <h2>履歴</h2>
@@ -12,6 +13,8 @@ export function WordHistory({ history }: Props) {
         {[...history].reverse().map((h, i) => (
           <li key={history.length - 1 - i} className="history-item">
             <span className="history-word">{h.word}</span>
+            {showGenre && h.genre && <span className="history-genre">{h.genre}</span>}
+            {h.forced && <span className="history-genre">🔤「{h.forced}」指定</span>}
             <span className="history-player">{h.player}</span>
           </li>
         ))}
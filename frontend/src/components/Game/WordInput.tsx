@@ -9,10 +9,12 @@ interface Props {
   isVoteActive: boolean;
   lastWordPlayer: string;
   myName: string;
+  // The number of words played, which tells a race which word we answered.
+  prompt?: number;
   onSend: (msg: OutgoingMessage) => void;
 }
 
-export function WordInput({ isMyTurn, currentTurn, currentWord, isVoteActive, lastWordPlayer, myName, onSend }: Props) {
+export function WordInput({ isMyTurn, currentTurn, currentWord, isVoteActive, lastWordPlayer, myName, prompt, onSend }: Props) {
   const [value, setValue] = useState('');
   const inputRef = useRef<HTMLInputElement>(null);
   const composingRef = useRef(false);
@@ -33,10 +35,10 @@ export function WordInput({ isMyTurn, currentTurn, currentWord, isVoteActive, la
   const submit = useCallback(() => {
     const word = value.trim();
     if (!word) return;
-    onSend({ type: 'answer', word });
+    onSend({ type: 'answer', word, prompt });
     setValue('');
     inputRef.current?.focus();
-  }, [value, onSend]);
+  }, [value, prompt, onSend]);
 
   const handleKeyDown = useCallback((e: React.KeyboardEvent) => {
     if (e.key === 'Enter') { e.preventDefault(); submit(); }
@@ -60,7 +62,7 @@ export function WordInput({ isMyTurn, currentTurn, currentWord, isVoteActive, la
         onCompositionStart={() => { composingRef.current = true; }}
         onCompositionEnd={handleCompositionEnd}
         onKeyDown={handleKeyDown}
-        placeholder={isMyTurn ? (isFirstWord ? '最初のことばを入力…' : 'ことばを入力…') : `${currentTurn}さんの番です…`}
+        placeholder={isMyTurn ? (isFirstWord ? '最初のことばを入力…' : 'ことばを入力…') : currentTurn ? `${currentTurn}さんの番です…` : '観戦中…'}
         disabled={!isMyTurn}
         autoComplete="off"
         lang="ja"
@@ -2,6 +2,7 @@ import { useCallback } from 'react';
 import type { OutgoingMessage } from '../../types/messages';
 import type { GameState, Action } from '../../hooks/useGameState';
 import { RuleBadges } from '../common/RuleBadges';
+import { RuleList } from '../common/RuleList';
 import { WaitingRoom } from '../Room/WaitingRoom';
 import { TurnIndicator } from './TurnIndicator';
 import { LivesDisplay } from './LivesDisplay';
@@ -9,8 +10,9 @@ import { CurrentWord } from './CurrentWord';
 import { Timer } from './Timer';
 import { WordInput } from './WordInput';
 import { WordHistory } from './WordHistory';
+import { ItemBar } from './ItemBar';
 import { PlayerSidebar } from './PlayerSidebar';
-import { getRoomLink, copyText } from '../../utils/helpers';
+import { getRoomLink, copyText, dailyGoalText, lengthUnitName } from '../../utils/helpers';
 
 interface Props {
   state: GameState;
@@ -19,6 +21,10 @@ interface Props {
 }
 
 export function GameRoom({ state, dispatch, onSend }: Props) {
+  const rotatingGenres = (state.currentSettings.genres || []).length > 0;
+  const race = !!state.currentSettings.race;
+  const alive = (state.currentLives[state.myName] ?? state.maxLives) > 0;
+
   const handleLeave = useCallback(() => {
     onSend({ type: 'leave_room' });
     dispatch({ type: 'LEAVE_ROOM' });
@@ -51,6 +57,7 @@ export function GameRoom({ state, dispatch, onSend }: Props) {
         <div className="game-rules">
           <RuleBadges settings={state.currentSettings} />
         </div>
+        <RuleList rules={state.rules} />
         <div className="game-header-actions">
           <button className="share-btn share-btn-x" style={{ padding: '0.3rem 0.6rem', fontSize: '0.75rem' }} onClick={handleShareX}>
             <span className="share-icon">𝕏</span>
@@ -76,21 +83,29 @@ export function GameRoom({ state, dispatch, onSend }: Props) {
         />
       ) : (
         <div>
-          <TurnIndicator currentTurn={state.currentTurn} myName={state.myName} turnOrder={state.turnOrder} />
+          {state.daily && <div className="daily-banner">🎯 {dailyGoalText(state.daily)}</div>}
+          <TurnIndicator currentTurn={state.currentTurn} myName={state.myName} turnOrder={state.turnOrder} race={race} alive={alive} bomb={state.currentSettings.bomb} />
           <LivesDisplay currentLives={state.currentLives} myName={state.myName} maxLives={state.maxLives} />
           <CurrentWord word={state.currentWord} />
+          {rotatingGenres && state.genre && <div className="genre-banner">🏷️ ジャンル: {state.genre}</div>}
+          {state.forcedKana && <div className="ladder-min">🔤 次は「{state.forcedKana}」から始めること</div>}
+          {state.currentSettings.ladder && state.ladderMin > 0 && (
+            <div className="ladder-min">📈 次は{state.ladderMin}{lengthUnitName(state.currentSettings.lengthUnit)}以上</div>
+          )}
           <Timer seconds={state.timerSeconds} max={state.timerMax} />
           <WordInput
-            isMyTurn={state.currentTurn === state.myName}
+            isMyTurn={race ? alive : state.currentTurn === state.myName}
             currentTurn={state.currentTurn}
+            prompt={race ? state.history.length : undefined}
             currentWord={state.currentWord}
             isVoteActive={state.isVoteActive}
             lastWordPlayer={state.lastWordPlayer}
             myName={state.myName}
             onSend={onSend}
           />
+          <ItemBar items={state.items[state.myName] || {}} isMyTurn={state.currentTurn === state.myName} onSend={onSend} />
           <div className="game-body">
-            <WordHistory history={state.history} />
+            <WordHistory history={state.history} showGenre={rotatingGenres} />
             <PlayerSidebar
               players={state.players}
               myName={state.myName}
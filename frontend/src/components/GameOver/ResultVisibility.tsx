@@ -0,0 +1,51 @@
+import { useState, useCallback } from 'react';
+import type { ResultVisibility as Visibility } from '../../types/messages';
+import { setResultVisibility } from '../../utils/helpers';
+
+const OPTIONS: { value: Visibility; label: string; hint: string }[] = [
+  { value: 'public', label: '🌐 公開', hint: '結果一覧に載り、検索もされます' },
+  { value: 'unlisted', label: '🔗 限定公開', hint: 'リンクを知っている人だけが見られます' },
+  { value: 'private', label: '🔒 非公開', hint: 'このブラウザからだけ見られます' },
+];
+
+interface Props {
+  resultId: string;
+  token: string;
+  visibility: Visibility;
+}
+
+// Lets the room owner publish, unlist or hide the saved result.
+export function ResultVisibility({ resultId, token, visibility }: Props) {
+  const [current, setCurrent] = useState<Visibility>(visibility);
+  const [saving, setSaving] = useState(false);
+  const [failed, setFailed] = useState(false);
+
+  const handleChange = useCallback(async (next: Visibility) => {
+    setSaving(true);
+    setFailed(false);
+    if (await setResultVisibility(resultId, token, next)) {
+      setCurrent(next);
+    } else {
+      setFailed(true);
+    }
+    setSaving(false);
+  }, [resultId, token]);
+
+  return (
+    <div className="result-visibility">
+      <p>👁️ 結果の公開範囲</p>
+      <div className="result-visibility-options">
+        {OPTIONS.map((o) => (
+          <button key={o.value} disabled={saving}
+            className={`result-visibility-btn${current === o.value ? ' selected' : ''}`}
+            onClick={() => current !== o.value && handleChange(o.value)}>
+            {o.label}
+          </button>
+        ))}
+      </div>
+      <p className="result-visibility-hint">
+        {failed ? '変更できませんでした' : OPTIONS.find((o) => o.value === current)?.hint}
+      </p>
+    </div>
+  );
+}
@@ -1,5 +1,8 @@
 import { useState, useCallback, useMemo } from 'react';
-import type { RoomSettings, HistoryEntry, OutgoingMessage } from '../../types/messages';
+import type { RoomSettings, HistoryEntry, OutgoingMessage, MatchSummary, DailyClear, ResultVisibility as Visibility } from '../../types/messages';
+import { formatDuration } from '../../utils/helpers';
+import { RuleOptions, pickRuleOptions } from '../common/RuleOptions';
+import { ResultVisibility } from './ResultVisibility';
 
 const DEFAULT_MAX_LIVES = 3;
 
@@ -11,6 +14,7 @@ interface GameOverData {
   history: HistoryEntry[];
   lives: Record<string, number>;
   resultId?: string;
+  daily?: DailyClear;
 }
 
 interface Props {
@@ -22,9 +26,12 @@ interface Props {
   onSend: (msg: OutgoingMessage) => void;
   onBackToLobby: () => void;
   lastShareURL: string;
+  resultOwner: { resultId: string; token: string; visibility: Visibility } | null;
+  match: MatchSummary | null;
+  rematch: { ready: string[]; totalPlayers: number } | null;
 }
 
-export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaRowNames, onSend, onBackToLobby, lastShareURL }: Props) {
+export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaRowNames, onSend, onBackToLobby, lastShareURL, resultOwner, match, rematch }: Props) {
   const [historyOpen, setHistoryOpen] = useState(false);
   const [settingsOpen, setSettingsOpen] = useState(false);
   const [copiedLink, setCopiedLink] = useState(false);
@@ -37,9 +44,18 @@ export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaR
   const [maxLives, setMaxLives] = useState(currentSettings.maxLives || DEFAULT_MAX_LIVES);
   const [selectedRows, setSelectedRows] = useState<string[]>(currentSettings.allowedRows || []);
   const [noDakuten, setNoDakuten] = useState(!!currentSettings.noDakuten);
+  const [rounds, setRounds] = useState(currentSettings.rounds || 1);
+  const [options, setOptions] = useState(() => pickRuleOptions(currentSettings));
+  const [password, setPassword] = useState('');
+  const [clearPassword, setClearPassword] = useState(false);
   const [waitingForHost, setWaitingForHost] = useState(false);
 
   const isOwner = myName === roomOwner;
+  // Between rounds of a match, everyone confirms the rematch instead of the
+  // owner starting the next game.
+  const matchRunning = !!match && match.bestOf > 1 && !match.over;
+  const readyCount = rematch?.ready.length ?? 0;
+  const iAmReady = !!rematch?.ready.includes(myName);
 
   const sorted = useMemo(() =>
     Object.entries(gameOver.scores).sort((a, b) => b[1] - a[1]),
@@ -53,6 +69,10 @@ export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaR
   } else if (gameOver.loser) {
     reason = `${gameOver.loser}さん - ${reason}`;
   }
+  if (gameOver.daily) {
+    reason = `🎉 クリア！ ${gameOver.daily.words}語・${formatDuration(gameOver.daily.durationMs)}` +
+      (gameOver.daily.rank ? `（今日の${gameOver.daily.rank}位）` : '');
+  }
 
   const chain = gameOver.history.map((h) => h.word).join(' → ');
 
@@ -65,9 +85,12 @@ export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaR
       timeLimit !== (s.timeLimit || 0) ||
       maxLives !== (s.maxLives || DEFAULT_MAX_LIVES) ||
       noDakuten !== !!s.noDakuten ||
+      rounds !== (s.rounds || 1) ||
+      password !== '' || clearPassword ||
+      JSON.stringify(options) !== JSON.stringify(pickRuleOptions(s)) ||
       JSON.stringify(selectedRows.length > 0 ? selectedRows : []) !== JSON.stringify(s.allowedRows || [])
     );
-  }, [minLen, maxLen, genre, timeLimit, maxLives, noDakuten, selectedRows, currentSettings]);
+  }, [minLen, maxLen, genre, timeLimit, maxLives, noDakuten, rounds, password, clearPassword, options, selectedRows, currentSettings]);
 
   const handlePlayAgain = useCallback(() => {
     if (!isOwner) {
@@ -76,17 +99,21 @@ export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaR
     }
     if (settingsChanged) {
       const newSettings: RoomSettings = {
+        ...currentSettings,
+        ...options,
         name: currentSettings.name || 'しりとりルーム',
         minLen, maxLen, genre, timeLimit, maxLives,
         allowedRows: selectedRows.length > 0 ? selectedRows : undefined,
         noDakuten: noDakuten || undefined,
-        private: currentSettings.private || undefined,
+        rounds: rounds > 1 ? rounds : undefined,
+        password: clearPassword ? undefined : password || undefined,
+        clearPassword: clearPassword || undefined,
       };
       onSend({ type: 'start_game', settings: newSettings });
     } else {
       onSend({ type: 'start_game' });
     }
-  }, [isOwner, settingsChanged, minLen, maxLen, genre, timeLimit, maxLives, selectedRows, noDakuten, currentSettings, onSend]);
+  }, [isOwner, settingsChanged, minLen, maxLen, genre, timeLimit, maxLives, selectedRows, noDakuten, rounds, password, clearPassword, options, currentSettings, onSend]);
 
   const shareURL = lastShareURL || (gameOver.resultId ? `${location.origin}/results/${gameOver.resultId}` : '');
 
@@ -144,6 +171,22 @@ export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaR
           ))}
         </ul>
 
+        {/* Match */}
+        {match && match.bestOf > 1 && (
+          <div className="match-summary">
+            <p className="match-summary-title">
+              {match.over
+                ? (match.winner ? `🏆 ${match.winner}さんがマッチに勝利！` : 'マッチは引き分け')
+                : `${match.bestOf}本勝負 — 第${match.rounds.length}戦終了`}
+            </p>
+            <ul className="match-wins">
+              {Object.entries(match.wins).sort((a, b) => b[1] - a[1]).map(([name, wins]) => (
+                <li key={name}>{name} <span className="match-wins-count">{wins}勝</span></li>
+              ))}
+            </ul>
+          </div>
+        )}
+
         {/* History */}
         <div className="game-over-history">
           <button className={`game-over-history-toggle${historyOpen ? ' open' : ''}`}
@@ -185,8 +228,13 @@ export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaR
           </div>
         )}
 
-        {/* Settings (owner only) */}
-        {isOwner && (
+        {/* Visibility (result owner only) */}
+        {resultOwner && resultOwner.resultId === gameOver.resultId && (
+          <ResultVisibility resultId={resultOwner.resultId} token={resultOwner.token} visibility={resultOwner.visibility} />
+        )}
+
+        {/* Settings (owner only; the daily puzzle's rules are fixed) */}
+        {isOwner && !gameOver.daily && (
           <div className="game-over-settings">
             <button className={`game-over-settings-toggle${settingsOpen ? ' open' : ''}`}
               onClick={() => setSettingsOpen(!settingsOpen)}>
@@ -231,8 +279,17 @@ export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaR
                     <option value={10}>❤️×10</option>
                   </select>
                 </div>
-                <div className="form-group"></div>
+                <div className="form-group">
+                  <label>ラウンド数（次のマッチから）</label>
+                  <select value={rounds} onChange={(e) => setRounds(Number(e.target.value))}>
+                    <option value={1}>1回勝負</option>
+                    <option value={3}>3本勝負</option>
+                    <option value={5}>5本勝負</option>
+                    <option value={7}>7本勝負</option>
+                  </select>
+                </div>
               </div>
+              <RuleOptions value={options} onChange={setOptions} />
               <div className="form-group">
                 <label>使用可能な行（未選択＝すべて）</label>
                 <div className="kana-row-grid">
@@ -251,11 +308,36 @@ export function ScoreBoard({ gameOver, currentSettings, myName, roomOwner, kanaR
                   濁音・半濁音禁止
                 </label>
               </div>
+              <div className="form-row">
+                <div className="form-group">
+                  <label>🔑 パスワード{currentSettings.hasPassword ? '（変更するときだけ入力）' : '（空欄＝なし）'}</label>
+                  <input type="password" maxLength={64} autoComplete="new-password" value={password}
+                    disabled={clearPassword} onChange={(e) => setPassword(e.target.value)} />
+                </div>
+                {currentSettings.hasPassword ? (
+                  <div className="form-group">
+                    <label className="kana-row-chip" style={{ display: 'inline-flex', cursor: 'pointer' }}>
+                      <input type="checkbox" checked={clearPassword} onChange={(e) => setClearPassword(e.target.checked)}
+                        style={{ display: 'inline', width: 'auto', marginRight: '0.3rem' }} />
+                      🔓 パスワードを外す
+                    </label>
+                  </div>
+                ) : (
+                  <div className="form-group"></div>
+                )}
+              </div>
             </div>
           </div>
         )}
 
-        {!waitingForHost ? (
+        {matchRunning ? (
+          <>
+            <button className="btn btn-primary btn-lg" onClick={() => onSend({ type: 'rematch' })} disabled={iAmReady} style={{ marginRight: '0.5rem' }}>
+              {iAmReady ? `✋ 準備OK (${readyCount}/${rematch?.totalPlayers ?? 0})` : `✋ 次のラウンドへ${readyCount > 0 ? ` (${readyCount}/${rematch?.totalPlayers ?? 0})` : ''}`}
+            </button>
+            <button className="btn btn-outline btn-lg" onClick={onBackToLobby}>🏠 ロビーへ</button>
+          </>
+        ) : !waitingForHost ? (
           <>
             <button className="btn btn-primary btn-lg" onClick={handlePlayAgain} style={{ marginRight: '0.5rem' }}>
               {settingsChanged ? '🔄 ルール変更して開始' : '🔄 もう一度'}
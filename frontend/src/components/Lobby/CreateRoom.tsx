@@ -1,5 +1,7 @@
 import { useState, useCallback } from 'react';
 import type { RoomSettings, OutgoingMessage } from '../../types/messages';
+import { RuleOptions } from '../common/RuleOptions';
+import type { RuleOptionValues } from '../common/RuleOptions';
 
 const DEFAULT_MAX_LIVES = 3;
 
@@ -19,6 +21,9 @@ export function CreateRoom({ playerName, kanaRowNames, onSend }: Props) {
   const [selectedRows, setSelectedRows] = useState<string[]>([]);
   const [noDakuten, setNoDakuten] = useState(false);
   const [isPrivate, setIsPrivate] = useState(false);
+  const [password, setPassword] = useState('');
+  const [rounds, setRounds] = useState(1);
+  const [options, setOptions] = useState<RuleOptionValues>({});
 
   const hasName = playerName.trim().length > 0;
 
@@ -31,6 +36,7 @@ export function CreateRoom({ playerName, kanaRowNames, onSend }: Props) {
   const handleCreate = () => {
     if (!hasName) return;
     const settings: RoomSettings = {
+      ...options,
       name: roomName.trim() || 'しりとりルーム',
       minLen: minLen || 1,
       maxLen: maxLen || 0,
@@ -40,6 +46,8 @@ export function CreateRoom({ playerName, kanaRowNames, onSend }: Props) {
       allowedRows: selectedRows.length > 0 ? selectedRows : undefined,
       noDakuten: noDakuten || undefined,
       private: isPrivate || undefined,
+      password: password || undefined,
+      rounds: rounds > 1 ? rounds : undefined,
     };
     onSend({ type: 'create_room', name: playerName.trim(), settings });
   };
@@ -90,8 +98,17 @@ export function CreateRoom({ playerName, kanaRowNames, onSend }: Props) {
                 <option value={10}>❤️×10</option>
               </select>
             </div>
-            <div className="form-group"></div>
+            <div className="form-group">
+              <label>ラウンド数</label>
+              <select value={rounds} onChange={(e) => setRounds(Number(e.target.value))}>
+                <option value={1}>1回勝負</option>
+                <option value={3}>3本勝負</option>
+                <option value={5}>5本勝負</option>
+                <option value={7}>7本勝負</option>
+              </select>
+            </div>
           </div>
+          <RuleOptions value={options} onChange={setOptions} />
           <div className="form-group">
             <label>使用可能な行（未選択＝すべて使用可能）</label>
             <div className="kana-row-grid">
@@ -117,6 +134,11 @@ export function CreateRoom({ playerName, kanaRowNames, onSend }: Props) {
               🔒 プライベートルーム（ロビーに表示しない）
             </label>
           </div>
+          <div className="form-group">
+            <label>🔑 パスワード（空欄＝なし）</label>
+            <input type="password" maxLength={64} autoComplete="new-password" value={password}
+              onChange={(e) => setPassword(e.target.value)} />
+          </div>
           <div className="lobby-btn-wrap" style={{ display: 'block' }}>
             <button className="btn btn-primary btn-block" onClick={handleCreate} disabled={!hasName}>
               ルームを作成
@@ -0,0 +1,54 @@
+import { useEffect, useState } from 'react';
+import type { DailyPuzzle, DailyEntry } from '../../types/messages';
+import { RuleBadges } from '../common/RuleBadges';
+import { dailyGoalText, formatDuration } from '../../utils/helpers';
+
+interface Props {
+  playerName: string;
+  onStart: () => void;
+}
+
+// Today's puzzle and the top of its leaderboard (GET /api/daily).
+export function DailyCard({ playerName, onStart }: Props) {
+  const [data, setData] = useState<{ puzzle: DailyPuzzle; leaderboard: DailyEntry[] } | null>(null);
+  const [failed, setFailed] = useState(false);
+  const hasName = playerName.trim().length > 0;
+
+  useEffect(() => {
+    fetch('/api/daily')
+      .then((res) => res.ok ? res.json() : Promise.reject(res.status))
+      .then(setData)
+      .catch(() => setFailed(true));
+  }, []);
+
+  const p = data?.puzzle;
+  return (
+    <div className="card daily-card slide-up" style={{ animationDelay: '0.05s' }}>
+      <h2>今日のしりとり {p && <span className="daily-date">{p.date}</span>}</h2>
+      <p className="daily-goal">
+        {p ? dailyGoalText(p) : failed ? '今日のお題を読み込めませんでした' : '読み込み中…'}
+      </p>
+      {p && (
+        <div className="daily-rules">
+          <RuleBadges settings={p.settings} />
+        </div>
+      )}
+      {data && data.leaderboard && data.leaderboard.length > 0 && (
+        <ol className="daily-leaderboard">
+          {data.leaderboard.slice(0, 5).map((e) => (
+            <li key={e.rank}>
+              {e.resultId ? <a href={`/results/${e.resultId}`}>{e.player}</a> : e.player}
+              {` — ${e.words}語・${formatDuration(e.durationMs)}`}
+            </li>
+          ))}
+        </ol>
+      )}
+      <div className="lobby-btn-wrap" style={{ display: 'block' }}>
+        <button className="btn btn-primary btn-block" onClick={onStart} disabled={!hasName || !p}>
+          挑戦する
+        </button>
+        {!hasName && <span className="lobby-btn-tooltip">ユーザー名を入力してください</span>}
+      </div>
+    </div>
+  );
+}
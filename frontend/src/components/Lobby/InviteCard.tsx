@@ -14,7 +14,7 @@ interface InviteRoomData {
 interface Props {
   inviteRoomId: string;
   playerName: string;
-  onJoin: () => void;
+  onJoin: (hasPassword: boolean) => void;
   onClear: () => void;
 }
 
@@ -49,7 +49,7 @@ export function InviteCard({ inviteRoomId, playerName, onJoin, onClear }: Props)
       </div>
       <div className="invite-actions">
         <div className="lobby-btn-wrap">
-          <button className="btn btn-primary" onClick={onJoin} disabled={!hasName}>参加する</button>
+          <button className="btn btn-primary" onClick={() => onJoin(!!roomData.settings?.hasPassword)} disabled={!hasName}>参加する</button>
           {!hasName && <span className="lobby-btn-tooltip">ユーザー名を入力してください</span>}
         </div>
         <button className="btn btn-outline" onClick={onClear}>無視</button>
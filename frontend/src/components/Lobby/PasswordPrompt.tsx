@@ -0,0 +1,35 @@
+import { useState } from 'react';
+
+interface Props {
+  roomId: string;
+  onJoin: (password: string) => void;
+  onCancel: () => void;
+}
+
+// PasswordPrompt asks for the password of a locked room. It stays open after
+// a wrong password so the player can try again.
+export function PasswordPrompt({ roomId, onJoin, onCancel }: Props) {
+  const [password, setPassword] = useState('');
+
+  const submit = () => {
+    if (!password) return;
+    onJoin(password);
+    setPassword('');
+  };
+
+  return (
+    <div className="card invite-card slide-up">
+      <div className="invite-info">
+        <div className="invite-room-title">🔑 パスワードが必要です</div>
+        <div className="invite-room-host">ルーム {roomId}</div>
+        <input type="password" placeholder="パスワード" maxLength={64} autoFocus value={password}
+          onChange={(e) => setPassword(e.target.value)}
+          onKeyDown={(e) => { if (e.key === 'Enter') { e.preventDefault(); submit(); } }} />
+      </div>
+      <div className="invite-actions">
+        <button className="btn btn-primary" onClick={submit} disabled={!password}>参加する</button>
+        <button className="btn btn-outline" onClick={onCancel}>やめる</button>
+      </div>
+    </div>
+  );
+}
@@ -4,7 +4,7 @@ import { getRoomLink } from '../../utils/helpers';
 interface Props {
   rooms: RoomInfo[];
   playerName: string;
-  onJoinRoom: (roomId: string) => void;
+  onJoinRoom: (roomId: string, hasPassword?: boolean) => void;
   onRefresh: () => void;
 }
 
@@ -31,19 +31,20 @@ export function RoomList({ rooms, playerName, onJoinRoom, onRefresh }: Props) {
               <li key={r.id} className="room-item fade-in">
                 <div className="room-info">
                   <a className="room-name" href={getRoomLink(r.id)}
-                    onClick={(e) => { e.preventDefault(); onJoinRoom(r.id); }}>
+                    onClick={(e) => { e.preventDefault(); onJoinRoom(r.id, r.settings?.hasPassword); }}>
                     {r.name}
                   </a>
                   <div className="room-meta">
                     <span>👥 {playerCount}人</span>
                     <span>🏷️ {genreLabel}</span>
                     <span>{statusLabel}</span>
+                    {r.settings?.hasPassword && <span>🔑 パスワード</span>}
                   </div>
                 </div>
                 <div className="room-actions">
                   <div className="lobby-btn-wrap">
                     <button className="btn btn-primary"
-                      onClick={() => onJoinRoom(r.id)}
+                      onClick={() => onJoinRoom(r.id, r.settings?.hasPassword)}
                       disabled={isPlaying || !hasName}>
                       参加
                     </button>
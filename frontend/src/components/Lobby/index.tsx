@@ -1,9 +1,11 @@
-import { useState, useCallback } from 'react';
+import { useState, useCallback, useEffect, useRef } from 'react';
 import type { RoomInfo, OutgoingMessage } from '../../types/messages';
 import type { GameState, Action } from '../../hooks/useGameState';
 import { CreateRoom } from './CreateRoom';
 import { RoomList } from './RoomList';
 import { InviteCard } from './InviteCard';
+import { DailyCard } from './DailyCard';
+import { PasswordPrompt } from './PasswordPrompt';
 
 interface Props {
   state: GameState;
@@ -11,32 +13,63 @@ interface Props {
   onSend: (msg: OutgoingMessage) => void;
 }
 
+// The server's reply to a join with a wrong password.
+const WRONG_PASSWORD = 'パスワードが違います';
+
 export function Lobby({ state, dispatch, onSend }: Props) {
   const [playerName, setPlayerName] = useState(state.myName);
+  const [passwordRoomId, setPasswordRoomId] = useState('');
+  const lastJoinRef = useRef('');
 
   const handleNameChange = (e: React.ChangeEvent<HTMLInputElement>) => {
     setPlayerName(e.target.value);
     dispatch({ type: 'SET_NAME', name: e.target.value });
   };
 
-  const handleJoinRoom = useCallback((roomId: string) => {
+  const sendJoin = useCallback((roomId: string, password?: string) => {
     const name = playerName.trim();
     if (!name) return;
+    lastJoinRef.current = roomId;
     dispatch({ type: 'SET_NAME', name });
-    onSend({ type: 'join', name, roomId });
+    onSend({ type: 'join', name, roomId, password });
     // Update URL
     const url = new URL(window.location.href);
     url.searchParams.set('room', roomId);
     window.history.replaceState({}, '', url.toString());
   }, [playerName, dispatch, onSend]);
 
+  // Locked rooms ask for their password first.
+  const handleJoinRoom = useCallback((roomId: string, hasPassword?: boolean) => {
+    if (!playerName.trim()) return;
+    if (hasPassword) {
+      setPasswordRoomId(roomId);
+      return;
+    }
+    sendJoin(roomId);
+  }, [playerName, sendJoin]);
+
+  // A room may have been locked since it was listed; ask for the password
+  // when the server turns the join away.
+  const lastMessage = state.messages[state.messages.length - 1];
+  useEffect(() => {
+    if (lastMessage?.type === 'error' && lastMessage.text === WRONG_PASSWORD && lastJoinRef.current) {
+      setPasswordRoomId(lastJoinRef.current);
+    }
+  }, [lastMessage]);
+
+  const handleStartDaily = useCallback(() => {
+    const name = playerName.trim();
+    if (!name) return;
+    onSend({ type: 'start_daily', name });
+  }, [playerName, onSend]);
+
   const handleRefresh = useCallback(() => {
     onSend({ type: 'get_rooms' });
   }, [onSend]);
 
-  const handleJoinInvite = useCallback(() => {
+  const handleJoinInvite = useCallback((hasPassword: boolean) => {
     if (!state.inviteRoomId) return;
-    handleJoinRoom(state.inviteRoomId);
+    handleJoinRoom(state.inviteRoomId, hasPassword);
   }, [state.inviteRoomId, handleJoinRoom]);
 
   const handleClearInvite = useCallback(() => {
@@ -57,8 +90,15 @@ export function Lobby({ state, dispatch, onSend }: Props) {
         </div>
       </div>
 
+      {passwordRoomId && (
+        <PasswordPrompt key={passwordRoomId} roomId={passwordRoomId} onJoin={(password) => sendJoin(passwordRoomId, password)}
+          onCancel={() => setPasswordRoomId('')} />
+      )}
+
       <CreateRoom playerName={playerName} kanaRowNames={state.kanaRowNames} onSend={onSend} />
 
+      <DailyCard playerName={playerName} onStart={handleStartDaily} />
+
       <InviteCard inviteRoomId={state.inviteRoomId} playerName={playerName}
         onJoin={handleJoinInvite} onClear={handleClearInvite} />
 
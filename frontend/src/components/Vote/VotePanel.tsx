@@ -79,7 +79,9 @@ export function VotePanel({ vote, myName, onSend, rebuttals }: Props) {
         <p className="vote-question">
           {isChallenge
             ? (vote.reason || 'この単語を認めますか？')
-            : `ジャンル「${vote.genre}」のリストにない単語です。認めますか？`}
+            : vote.reason
+              ? `${vote.reason}。認めますか？`
+              : `ジャンル「${vote.genre}」のリストにない単語です。認めますか？`}
         </p>
 
         {/* Vote buttons / rebuttal / waiting */}
@@ -0,0 +1,15 @@
+interface Props {
+  message: string;
+  onDismiss: () => void;
+}
+
+// NoticeBanner shows an admin announcement, or why the player was taken out
+// of the room, until it is dismissed.
+export function NoticeBanner({ message, onDismiss }: Props) {
+  return (
+    <div className="notice-banner" role="status">
+      <span>📢 {message}</span>
+      <button className="notice-close" onClick={onDismiss} aria-label="閉じる">×</button>
+    </div>
+  );
+}
@@ -1,7 +1,14 @@
 import type { RoomSettings } from '../../types/messages';
+import { lengthUnitName } from '../../utils/helpers';
 
 const DEFAULT_MAX_LIVES = 3;
 
+const CHAIN_MODE_LABELS: Record<string, string> = {
+  reverse: '🔁 逆しりとり',
+  two: '🔗 2音しりとり',
+  double: '↔️ 両端しりとり',
+};
+
 interface Props {
   settings: RoomSettings;
   showPrivate?: boolean;
@@ -13,15 +20,31 @@ export function RuleBadges({ settings, showPrivate, owner, playerCount }: Props)
   const s = settings;
   const badges: string[] = [];
   if (showPrivate && s.private) badges.push('🔒 プライベート');
+  if (s.hasPassword) badges.push('🔑 パスワード');
   if (owner) badges.push(`👑 ホスト: ${owner}`);
   if (playerCount !== undefined) badges.push(`👥 ${playerCount}人`);
   if (s.genre) badges.push(`🏷️ ${s.genre}`);
-  if (s.minLen > 1) badges.push(`最少${s.minLen}文字`);
-  if (s.maxLen > 0) badges.push(`最大${s.maxLen}文字`);
+  if (s.minLen > 1) badges.push(`最少${s.minLen}${lengthUnitName(s.lengthUnit)}`);
+  if (s.maxLen > 0) badges.push(`最大${s.maxLen}${lengthUnitName(s.lengthUnit)}`);
   if (s.timeLimit > 0) badges.push(`⏱️ ${s.timeLimit}秒`);
   if (s.allowedRows && s.allowedRows.length > 0) badges.push(`🎯 ${s.allowedRows.join('・')}`);
   if (s.noDakuten) badges.push('🚫 濁音・半濁音禁止');
+  if (s.chainMode && CHAIN_MODE_LABELS[s.chainMode]) badges.push(CHAIN_MODE_LABELS[s.chainMode]);
+  if (s.ladder) badges.push('📈 はしご');
+  if (s.race) badges.push('⚡ 早い者勝ち');
+  if (s.bomb) badges.push('💣 爆弾');
+  if (s.passes && s.passes > 0) badges.push(`⏭️ パス×${s.passes}`);
+  if (s.powerUps) badges.push('🎁 アイテム');
+  if (s.genres && s.genres.length > 0) {
+    badges.push(`🔄 ${s.genres.join(s.genreOrder === 'random' ? '・' : '→')}` +
+      (s.genreEvery && s.genreEvery > 1 ? `（${s.genreEvery}語ごと）` : ''));
+  }
+  if (s.customRules && s.customRules.length > 0) badges.push(`🧩 カスタムルール×${s.customRules.length}`);
+  if (s.startMode === 'random') badges.push('🎲 ランダムな言葉から');
+  if (s.startMode === 'fixed' && s.startWord) badges.push(`▶️ 「${s.startWord}」から`);
+  if (s.startMode === 'kana') badges.push('🔤 ランダムな文字から');
   badges.push(`❤️ ライフ${s.maxLives || DEFAULT_MAX_LIVES}`);
+  if (s.rounds && s.rounds > 1) badges.push(`🏁 ${s.rounds}本勝負`);
 
   return (
     <>
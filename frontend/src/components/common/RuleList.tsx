@@ -0,0 +1,30 @@
+import type { RuleInfo } from '../../types/messages';
+
+const RULE_OUTCOMES: Record<RuleInfo['outcome'], string> = {
+  reject: 'やり直し',
+  penalty: 'ライフ−1',
+  vote: '投票',
+};
+
+interface Props {
+  rules: RuleInfo[];
+}
+
+// The validation rules the server reports for the room, and what breaking
+// each one costs.
+export function RuleList({ rules }: Props) {
+  if (rules.length === 0) return null;
+  return (
+    <details className="rule-details">
+      <summary>📜 ルール一覧</summary>
+      <ul className="rule-list">
+        {rules.map((r) => (
+          <li key={r.id} className={`rule-item rule-${r.outcome}`}>
+            <span>{r.description}</span>
+            <span className="rule-outcome">{RULE_OUTCOMES[r.outcome] || r.outcome}</span>
+          </li>
+        ))}
+      </ul>
+    </details>
+  );
+}
@@ -0,0 +1,207 @@
+import { useState } from 'react';
+import type { RoomSettings, CustomRule } from '../../types/messages';
+
+// Room options beyond the basic length, genre, time and lives settings,
+// shared by room creation and the rule editor on the game over screen.
+export type RuleOptionValues = Pick<RoomSettings, 'startMode' | 'startWord' | 'lengthUnit' | 'customRules' | 'chainMode' | 'ladder' | 'race' | 'bomb' | 'passes' | 'powerUps' | 'genres' | 'genreEvery' | 'genreOrder'>;
+
+const RULE_OPTION_KEYS: (keyof RuleOptionValues)[] = ['startMode', 'startWord', 'lengthUnit', 'customRules', 'chainMode', 'ladder', 'race', 'bomb', 'passes', 'powerUps', 'genres', 'genreEvery', 'genreOrder'];
+
+export function pickRuleOptions(s: RoomSettings): RuleOptionValues {
+  const picked: RuleOptionValues = {};
+  for (const key of RULE_OPTION_KEYS) {
+    if (s[key] !== undefined) Object.assign(picked, { [key]: s[key] });
+  }
+  return picked;
+}
+
+// Custom rules are edited as text: one CEL expression per line, optionally
+// followed by "# message".
+function formatCustomRules(rules?: CustomRule[]): string {
+  return (rules || []).map((r) => r.message ? `${r.expr} # ${r.message}` : r.expr).join('\n');
+}
+
+function parseCustomRules(text: string, penalty: boolean): CustomRule[] | undefined {
+  const rules = text.split('\n')
+    .map((line) => {
+      const i = line.indexOf('#');
+      const expr = (i < 0 ? line : line.slice(0, i)).trim();
+      const message = i < 0 ? '' : line.slice(i + 1).trim();
+      return { expr, message: message || undefined, penalty: penalty || undefined };
+    })
+    .filter((r) => r.expr);
+  return rules.length > 0 ? rules : undefined;
+}
+
+interface Props {
+  value: RuleOptionValues;
+  // An option that is turned off stays in the value as undefined, so
+  // spreading the value over existing settings clears it.
+  onChange: (value: RuleOptionValues) => void;
+}
+
+export function RuleOptions({ value, onChange }: Props) {
+  const startMode = value.startMode || 'owner';
+  const [rulesText, setRulesText] = useState(() => formatCustomRules(value.customRules));
+  const [genresText, setGenresText] = useState(() => (value.genres || []).join('、'));
+  const [rulesPenalty, setRulesPenalty] = useState(() => !!value.customRules?.some((r) => r.penalty));
+
+  const updateGenres = (text: string) => {
+    setGenresText(text);
+    const genres = text.split(/[,、，]/).map((g) => g.trim()).filter(Boolean);
+    if (genres.length === 0) {
+      onChange({ ...value, genres: undefined, genreEvery: undefined, genreOrder: undefined });
+      return;
+    }
+    onChange({ ...value, genres });
+  };
+
+  const updateCustomRules = (text: string, penalty: boolean) => {
+    setRulesText(text);
+    setRulesPenalty(penalty);
+    onChange({ ...value, customRules: parseCustomRules(text, penalty) });
+  };
+
+  return (
+    <>
+      <div className="form-row">
+        <div className="form-group">
+          <label>文字数の数え方</label>
+          <select value={value.lengthUnit || ''} onChange={(e) => onChange({
+            ...value,
+            lengthUnit: (e.target.value || undefined) as RoomSettings['lengthUnit'],
+          })}>
+            <option value="">文字（きゃく＝3）</option>
+            <option value="mora">音（きゃく＝2、らーめん＝4）</option>
+            <option value="syllable">音節（っ・ーは数えない、らーめん＝3）</option>
+          </select>
+        </div>
+        <div className="form-group">
+          <label>つなげ方</label>
+          <select value={value.chainMode || ''} onChange={(e) => onChange({
+            ...value,
+            chainMode: (e.target.value || undefined) as RoomSettings['chainMode'],
+          })}>
+            <option value="">しりとり（最後の文字から始める）</option>
+            <option value="reverse">逆しりとり（最初の文字で終わる）</option>
+            <option value="two">2音しりとり（最後の2音から始める）</option>
+            <option value="double">両端しりとり（どちらの端でもOK）</option>
+          </select>
+        </div>
+      </div>
+      <div className="form-row">
+        <div className="form-group">
+          <label>最初の言葉</label>
+          <select value={startMode} onChange={(e) => {
+            const mode = e.target.value as NonNullable<RoomSettings['startMode']>;
+            onChange({
+              ...value,
+              startMode: mode === 'owner' ? undefined : mode,
+              startWord: mode === 'fixed' ? value.startWord : undefined,
+            });
+          }}>
+            <option value="owner">ホストが自由に決める</option>
+            <option value="random">ランダムな言葉</option>
+            <option value="fixed">言葉を指定</option>
+            <option value="kana">ランダムな文字から</option>
+          </select>
+        </div>
+        {startMode === 'fixed' ? (
+          <div className="form-group">
+            <label>最初の言葉（ひらがな・カタカナ）</label>
+            <input type="text" placeholder="しりとり" maxLength={20} value={value.startWord || ''}
+              onChange={(e) => onChange({ ...value, startWord: e.target.value.trim() || undefined })} />
+          </div>
+        ) : (
+          <div className="form-group"></div>
+        )}
+      </div>
+      <div className="form-group">
+        <label>ジャンルを順番に変える（カンマ区切り、空欄＝変えない）</label>
+        <input type="text" placeholder="例: 食べ物、動物、国名" maxLength={200} value={genresText}
+          onChange={(e) => updateGenres(e.target.value)} />
+      </div>
+      {value.genres && (
+        <div className="form-row">
+          <div className="form-group">
+            <label>ジャンルを変える間隔（語）</label>
+            <input type="number" min={1} max={20} value={value.genreEvery || 1}
+              onChange={(e) => onChange({ ...value, genreEvery: Number(e.target.value) > 1 ? Number(e.target.value) : undefined })} />
+          </div>
+          <div className="form-group">
+            <label>ジャンルの順番</label>
+            <select value={value.genreOrder || ''} onChange={(e) => onChange({
+              ...value,
+              genreOrder: (e.target.value || undefined) as RoomSettings['genreOrder'],
+            })}>
+              <option value="">リストの順</option>
+              <option value="random">ランダム</option>
+            </select>
+          </div>
+        </div>
+      )}
+      <div className="form-group">
+        <label className="kana-row-chip" style={{ display: 'inline-flex', cursor: 'pointer' }}>
+          <input type="checkbox" checked={!!value.ladder} onChange={(e) => onChange({ ...value, ladder: e.target.checked || undefined })}
+            style={{ display: 'inline', width: 'auto', marginRight: '0.3rem' }} />
+          📈 はしごモード（前の言葉より長く。ミスで最少文字数に戻る）
+        </label>
+      </div>
+      <div className="form-group">
+        <label className="kana-row-chip" style={{ display: 'inline-flex', cursor: 'pointer' }}>
+          <input type="checkbox" checked={!!value.race} onChange={(e) => onChange({
+            ...value,
+            race: e.target.checked || undefined,
+            bomb: e.target.checked ? undefined : value.bomb,
+            passes: e.target.checked ? undefined : value.passes,
+            powerUps: e.target.checked ? undefined : value.powerUps,
+          })}
+            style={{ display: 'inline', width: 'auto', marginRight: '0.3rem' }} />
+          ⚡ 早い者勝ちモード（全員が同時に答え、最初の正解が得点。まちがいはライフ−1）
+        </label>
+      </div>
+      <div className="form-group">
+        <label className="kana-row-chip" style={{ display: 'inline-flex', cursor: 'pointer' }}>
+          <input type="checkbox" checked={!!value.bomb} onChange={(e) => onChange({
+            ...value,
+            bomb: e.target.checked || undefined,
+            race: e.target.checked ? undefined : value.race,
+          })}
+            style={{ display: 'inline', width: 'auto', marginRight: '0.3rem' }} />
+          💣 爆弾モード（残り時間の見えない爆弾が回り、爆発したときの番の人がライフ−1）
+        </label>
+      </div>
+      {!value.race && (
+        <div className="form-row">
+          <div className="form-group">
+            <label>パスの回数（1人あたり）</label>
+            <select value={value.passes || 0} onChange={(e) => onChange({ ...value, passes: Number(e.target.value) || undefined })}>
+              {[0, 1, 2, 3, 4, 5].map((n) => (
+                <option key={n} value={n}>{n === 0 ? 'なし' : `${n}回`}</option>
+              ))}
+            </select>
+          </div>
+          <div className="form-group">
+            <label className="kana-row-chip" style={{ display: 'inline-flex', cursor: 'pointer' }}>
+              <input type="checkbox" checked={!!value.powerUps} onChange={(e) => onChange({ ...value, powerUps: e.target.checked || undefined })}
+                style={{ display: 'inline', width: 'auto', marginRight: '0.3rem' }} />
+              🎁 アイテム（順番逆転・頭文字指定・+5秒を1つずつ）
+            </label>
+          </div>
+        </div>
+      )}
+      <div className="form-group">
+        <label>カスタムルール（1行に1つ、CEL式 # メッセージ）</label>
+        <textarea rows={2} value={rulesText}
+          placeholder={'length >= turn / 5 + 2 # だんだん長く！\n!hiragana.contains("う") # 「う」禁止'}
+          onChange={(e) => updateCustomRules(e.target.value, rulesPenalty)} />
+        <p className="form-hint">使える変数: word, hiragana, length, first, last, previous, turn, player</p>
+        <label className="kana-row-chip" style={{ display: 'inline-flex', cursor: 'pointer' }}>
+          <input type="checkbox" checked={rulesPenalty} onChange={(e) => updateCustomRules(rulesText, e.target.checked)}
+            style={{ display: 'inline', width: 'auto', marginRight: '0.3rem' }} />
+          カスタムルール違反でライフ−1
+        </label>
+      </div>
+    </>
+  );
+}
@@ -0,0 +1,29 @@
+import { useEffect, useState } from 'react';
+
+interface Props {
+  message: string;
+  endsAt: number;
+  onDismiss: () => void;
+}
+
+// ShutdownBanner counts down to an announced server restart.
+export function ShutdownBanner({ message, endsAt, onDismiss }: Props) {
+  const [now, setNow] = useState(() => Date.now());
+
+  useEffect(() => {
+    const interval = setInterval(() => setNow(Date.now()), 1000);
+    return () => clearInterval(interval);
+  }, []);
+
+  const secs = Math.max(0, Math.ceil((endsAt - now) / 1000));
+
+  return (
+    <div className="notice-banner notice-warning" role="alert">
+      <span>
+        🛠️ {secs > 0 ? `あと${secs}秒でサーバーが停止します。` : 'サーバーを再起動しています。しばらくしてから再読み込みしてください。'}
+        <span className="notice-detail">{message}</span>
+      </span>
+      <button className="notice-close" onClick={onDismiss} aria-label="閉じる">×</button>
+    </div>
+  );
+}
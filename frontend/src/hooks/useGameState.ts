@@ -1,5 +1,6 @@
 import { useReducer } from 'react';
-import type { RoomSettings, RoomInfo, HistoryEntry, IncomingMessage } from '../types/messages';
+import { dailyGoalText } from '../utils/helpers';
+import type { RoomSettings, RoomInfo, HistoryEntry, IncomingMessage, ResultVisibility, MatchSummary, DailyPuzzle, DailyClear, RuleInfo, ItemType } from '../types/messages';
 
 const DEFAULT_MAX_LIVES = 3;
 
@@ -23,6 +24,7 @@ export interface GameState {
   currentSettings: RoomSettings;
   roomOwner: string;
   waitingPlayers: string[];
+  rules: RuleInfo[];
   // Game
   isPlaying: boolean;
   currentTurn: string;
@@ -35,6 +37,18 @@ export interface GameState {
   maxLives: number;
   currentLives: Record<string, number>;
   lastWordPlayer: string;
+  // Active genre in rooms that rotate genres
+  genre: string;
+  // Items left per player, and the kana a force item requires next
+  items: Record<string, Partial<Record<ItemType, number>>>;
+  forcedKana: string;
+  // Ladder mode: the length the next word must reach
+  ladderMin: number;
+  // Best-of-N match and the rematch ready-check between its rounds
+  match: MatchSummary | null;
+  rematch: { ready: string[]; totalPlayers: number } | null;
+  // Set while playing the daily challenge
+  daily: DailyPuzzle | null;
   // Vote
   isVoteActive: boolean;
   vote: {
@@ -58,10 +72,17 @@ export interface GameState {
     history: HistoryEntry[];
     lives: Record<string, number>;
     resultId?: string;
+    daily?: DailyClear;
   } | null;
+  // Sent only to the room owner, just before game_over
+  resultOwner: { resultId: string; token: string; visibility: ResultVisibility } | null;
   // Messages
   messages: { text: string; type?: string; ts: string }[];
   toasts: Toast[];
+  // Announced server restart; endsAt is in ms since the epoch
+  shutdown: { message: string; endsAt: number } | null;
+  // Admin announcement, or why the room was left on the server's side
+  notice: string;
   // Reconnect
   wasInRoom: string;
   wasRoomOwner: boolean;
@@ -89,6 +110,7 @@ const initialState: GameState = {
   currentSettings: { ...defaultSettings },
   roomOwner: '',
   waitingPlayers: [],
+  rules: [],
   // Game
   isPlaying: false,
   currentTurn: '',
@@ -101,14 +123,24 @@ const initialState: GameState = {
   maxLives: DEFAULT_MAX_LIVES,
   currentLives: {},
   lastWordPlayer: '',
+  genre: '',
+  items: {},
+  forcedKana: '',
+  ladderMin: 0,
+  match: null,
+  rematch: null,
+  daily: null,
   // Vote
   isVoteActive: false,
   vote: null,
   // Game Over
   gameOver: null,
+  resultOwner: null,
   // Messages
   messages: [],
   toasts: [],
+  shutdown: null,
+  notice: '',
   // Reconnect
   wasInRoom: '',
   wasRoomOwner: false,
@@ -130,6 +162,9 @@ type Action =
   | { type: 'WORD_ACCEPTED'; msg: Extract<IncomingMessage, { type: 'word_accepted' }> }
   | { type: 'ANSWER_REJECTED'; message: string }
   | { type: 'TIMER'; timeLeft: number }
+  | { type: 'GENRE_CHANGED'; genre: string }
+  | { type: 'BOMB_EXPLODED'; player: string; currentTurn: string }
+  | { type: 'ITEM_USED'; msg: Extract<IncomingMessage, { type: 'item_used' }> }
   | { type: 'GAME_OVER'; msg: Extract<IncomingMessage, { type: 'game_over' }> }
   | { type: 'VOTE_REQUEST'; msg: Extract<IncomingMessage, { type: 'vote_request' }> }
   | { type: 'VOTE_UPDATE'; msg: Extract<IncomingMessage, { type: 'vote_update' }> }
@@ -138,12 +173,18 @@ type Action =
   | { type: 'CHALLENGE_WITHDRAWN'; msg: Extract<IncomingMessage, { type: 'challenge_withdrawn' }> }
   | { type: 'PENALTY'; msg: Extract<IncomingMessage, { type: 'penalty' }> }
   | { type: 'TURN_UPDATE'; msg: Extract<IncomingMessage, { type: 'turn_update' }> }
-  | { type: 'SETTINGS_UPDATED'; settings: RoomSettings }
+  | { type: 'SETTINGS_UPDATED'; settings: RoomSettings; rules?: RuleInfo[] }
   | { type: 'LEAVE_ROOM' }
   | { type: 'ADD_MESSAGE'; text: string; msgType?: string }
   | { type: 'ADD_TOAST'; toast: Toast }
   | { type: 'REMOVE_TOAST'; id: number }
+  | { type: 'SERVER_SHUTDOWN'; countdown: number; message: string }
+  | { type: 'DISMISS_SHUTDOWN' }
+  | { type: 'NOTICE'; message: string }
+  | { type: 'DISMISS_NOTICE' }
   | { type: 'SET_SHARE_URL'; url: string }
+  | { type: 'REMATCH_UPDATE'; msg: Extract<IncomingMessage, { type: 'rematch_update' }> }
+  | { type: 'RESULT_OWNER'; msg: Extract<IncomingMessage, { type: 'result_owner' }> }
   | { type: 'CLOSE_GAME_OVER' }
   | { type: 'REMEMBER_ROOM' };
 
@@ -207,6 +248,7 @@ function reducer(state: GameState, action: Action): GameState {
         roomOwner: msg.owner,
         currentSettings: msg.settings,
         waitingPlayers: msg.players.map((p) => p.name),
+        rules: msg.rules || [],
         isPlaying,
         currentTurn: msg.currentTurn,
         turnOrder: msg.turnOrder,
@@ -216,6 +258,12 @@ function reducer(state: GameState, action: Action): GameState {
         maxLives: msg.maxLives || msg.settings.maxLives || DEFAULT_MAX_LIVES,
         currentLives: msg.lives,
         timerMax: msg.settings.timeLimit || 30,
+        genre: msg.genre ?? '',
+        items: msg.items ?? {},
+        forcedKana: msg.forcedKana ?? '',
+        ladderMin: msg.ladderMin ?? 0,
+        match: msg.match ?? null,
+        daily: msg.daily ?? null,
         gameOver: null,
       };
     }
@@ -248,6 +296,7 @@ function reducer(state: GameState, action: Action): GameState {
         ...state,
         isPlaying: true,
         currentWord: msg.currentWord || msg.firstWord,
+        rules: msg.rules || state.rules,
         turnOrder: msg.turnOrder,
         currentTurn: msg.currentTurn,
         players,
@@ -257,16 +306,31 @@ function reducer(state: GameState, action: Action): GameState {
         timerSeconds: msg.timeLimit,
         timerMax: msg.timeLimit,
         lastWordPlayer: '',
+        genre: msg.genre ?? '',
+        items: msg.items ?? {},
+        forcedKana: '',
+        ladderMin: msg.ladderMin ?? 0,
+        match: msg.match ?? state.match,
+        rematch: null,
+        daily: msg.daily ?? state.daily,
         gameOver: null,
+        resultOwner: null,
         isVoteActive: false,
         vote: null,
       };
+      if (updated.daily) {
+        return addMessage(updated, dailyGoalText(updated.daily), 'success');
+      }
+      if (msg.firstWord) {
+        return addMessage(updated, `ゲーム開始！「${msg.firstWord}」に続けて${msg.currentTurn}さんから始めます`, 'info');
+      }
       return addMessage(updated, `ゲーム開始！ 最初の文字: ${msg.currentWord || msg.firstWord}`, 'info');
     }
 
     case 'WORD_ACCEPTED': {
       const { msg } = action;
-      const newHistory = [...state.history, { word: msg.word, player: msg.player }];
+      // The word was played under the genre active before it was accepted.
+      const newHistory = [...state.history, { word: msg.word, player: msg.player, genre: state.genre || undefined, responseMs: msg.responseMs, forced: state.forcedKana || undefined }];
       const players = buildPlayersFromMaps(state.turnOrder, msg.scores, msg.lives);
       return {
         ...state,
@@ -276,6 +340,9 @@ function reducer(state: GameState, action: Action): GameState {
         currentLives: msg.lives,
         currentTurn: msg.currentTurn,
         lastWordPlayer: msg.player,
+        ladderMin: msg.ladderMin ?? state.ladderMin,
+        genre: msg.genre ?? state.genre,
+        forcedKana: msg.forcedKana ?? '',
       };
     }
 
@@ -286,6 +353,33 @@ function reducer(state: GameState, action: Action): GameState {
     case 'TIMER':
       return { ...state, timerSeconds: action.timeLeft };
 
+    // The life the bomb costs follows as a penalty message.
+    case 'BOMB_EXPLODED':
+      return addMessage(
+        { ...state, currentTurn: action.currentTurn || state.currentTurn },
+        `💥 ${action.player}さんの手元で爆弾が爆発しました！`,
+        'error',
+      );
+
+    case 'ITEM_USED': {
+      const { msg } = action;
+      const scores = Object.fromEntries(state.players.map((p) => [p.name, p.score]));
+      const updated: GameState = {
+        ...state,
+        items: msg.items,
+        currentTurn: msg.currentTurn,
+        turnOrder: msg.turnOrder,
+        players: buildPlayersFromMaps(msg.turnOrder, scores, state.currentLives),
+        forcedKana: msg.forcedKana ?? '',
+        timerSeconds: msg.timeLeft ?? state.timerSeconds,
+      };
+      const kana = msg.kana ? `（次は「${msg.kana}」から）` : '';
+      return addMessage(updated, `${msg.player}さんが「${msg.label}」を使いました${kana}`, 'info');
+    }
+
+    case 'GENRE_CHANGED':
+      return addMessage({ ...state, genre: action.genre }, `🔄 ジャンルが「${action.genre}」に変わりました！`, 'info');
+
     case 'GAME_OVER': {
       const { msg } = action;
       const updated: GameState = {
@@ -299,7 +393,10 @@ function reducer(state: GameState, action: Action): GameState {
           history: msg.history,
           lives: msg.lives,
           resultId: msg.resultId,
+          daily: msg.daily,
         },
+        match: msg.match ?? state.match,
+        rematch: null,
         isVoteActive: false,
         vote: null,
       };
@@ -372,6 +469,15 @@ function reducer(state: GameState, action: Action): GameState {
       if (msg.currentTurn !== undefined) {
         updated = { ...updated, currentTurn: msg.currentTurn };
       }
+      if (msg.ladderMin !== undefined) {
+        updated = { ...updated, ladderMin: msg.ladderMin };
+      }
+      if (msg.genre !== undefined) {
+        updated = { ...updated, genre: msg.genre };
+      }
+      if (msg.reverted) {
+        updated = { ...updated, forcedKana: msg.forcedKana ?? '' };
+      }
       const resultMsg = msg.accepted
         ? `チャレンジ成功: ${msg.word} - ${msg.message ?? ''}`
         : `チャレンジ失敗: ${msg.word} - ${msg.message ?? ''}`;
@@ -402,6 +508,7 @@ function reducer(state: GameState, action: Action): GameState {
         ...state,
         currentLives: newLives,
         players,
+        ladderMin: msg.ladderMin ?? state.ladderMin,
       };
       const elimMsg = msg.eliminated ? `（脱落！）` : '';
       return addMessage(updated, `${msg.player} にペナルティ: ${msg.reason} (残りライフ: ${msg.lives})${elimMsg}`, 'info');
@@ -421,7 +528,7 @@ function reducer(state: GameState, action: Action): GameState {
     }
 
     case 'SETTINGS_UPDATED':
-      return { ...state, currentSettings: action.settings };
+      return { ...state, currentSettings: action.settings, rules: action.rules || state.rules };
 
     case 'LEAVE_ROOM':
       return {
@@ -431,6 +538,7 @@ function reducer(state: GameState, action: Action): GameState {
         roomOwner: '',
         currentSettings: { ...defaultSettings },
         waitingPlayers: [],
+        rules: [],
         isPlaying: false,
         currentTurn: '',
         turnOrder: [],
@@ -441,9 +549,17 @@ function reducer(state: GameState, action: Action): GameState {
         maxLives: DEFAULT_MAX_LIVES,
         currentLives: {},
         lastWordPlayer: '',
+        genre: '',
+        items: {},
+        forcedKana: '',
+        ladderMin: 0,
+        match: null,
+        rematch: null,
+        daily: null,
         isVoteActive: false,
         vote: null,
         gameOver: null,
+        resultOwner: null,
       };
 
     case 'ADD_MESSAGE':
@@ -455,9 +571,34 @@ function reducer(state: GameState, action: Action): GameState {
     case 'REMOVE_TOAST':
       return { ...state, toasts: state.toasts.filter((t) => t.id !== action.id) };
 
+    case 'SERVER_SHUTDOWN': {
+      const updated = { ...state, shutdown: { message: action.message, endsAt: Date.now() + action.countdown * 1000 } };
+      return addMessage(updated, action.message, 'error');
+    }
+
+    case 'DISMISS_SHUTDOWN':
+      return { ...state, shutdown: null };
+
+    case 'NOTICE':
+      return addMessage({ ...state, notice: action.message }, `📢 ${action.message}`, 'info');
+
+    case 'DISMISS_NOTICE':
+      return { ...state, notice: '' };
+
     case 'SET_SHARE_URL':
       return { ...state, lastShareURL: action.url };
 
+    case 'REMATCH_UPDATE': {
+      const { msg } = action;
+      const updated = { ...state, rematch: { ready: msg.ready, totalPlayers: msg.totalPlayers } };
+      return addMessage(updated, `${msg.player} が次のラウンドの準備OK (${msg.ready.length}/${msg.totalPlayers})`, 'info');
+    }
+
+    case 'RESULT_OWNER': {
+      const { msg } = action;
+      return { ...state, resultOwner: { resultId: msg.resultId, token: msg.token, visibility: msg.visibility } };
+    }
+
     case 'CLOSE_GAME_OVER':
       return { ...state, gameOver: null };
 
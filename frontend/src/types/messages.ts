@@ -1,9 +1,11 @@
 // === Outgoing messages (client → server) ===
 export type OutgoingMessage =
   | { type: 'create_room'; name: string; settings: RoomSettings }
-  | { type: 'join'; name: string; roomId: string }
+  | { type: 'start_daily'; name: string }
+  | { type: 'join'; name: string; roomId: string; password?: string }
   | { type: 'start_game'; settings?: RoomSettings }
-  | { type: 'answer'; word: string }
+  | { type: 'answer'; word: string; prompt?: number }
+  | { type: 'use_item'; item: ItemType; kana?: string }
   | { type: 'leave_room' }
   | { type: 'get_rooms' }
   | { type: 'get_genres' }
@@ -11,33 +13,47 @@ export type OutgoingMessage =
   | { type: 'vote'; accept: boolean }
   | { type: 'withdraw_challenge' }
   | { type: 'rebuttal'; rebuttal: string }
+  | { type: 'rematch' }
   | { type: 'update_settings'; settings: RoomSettings };
 
 // === Incoming messages (server → client) ===
 export type IncomingMessage =
   | { type: 'rooms'; rooms: RoomInfo[] }
   | { type: 'genres'; kanaRows: string[] }
-  | { type: 'room_joined'; roomId: string; owner: string; settings: RoomSettings; players: PlayerInfo[]; scores: Record<string, number>; lives: Record<string, number>; maxLives: number; history: HistoryEntry[]; turnOrder: string[]; currentTurn: string; currentWord: string; status: string }
-  | { type: 'room_state'; roomId: string; owner: string; settings: RoomSettings; players: PlayerInfo[]; scores: Record<string, number>; lives: Record<string, number>; maxLives: number; history: HistoryEntry[]; turnOrder: string[]; currentTurn: string; currentWord: string; status: string }
+  | { type: 'room_joined'; roomId: string; owner: string; settings: RoomSettings; players: PlayerInfo[]; scores: Record<string, number>; lives: Record<string, number>; maxLives: number; history: HistoryEntry[]; turnOrder: string[]; currentTurn: string; currentWord: string; status: string; match?: MatchSummary; daily?: DailyPuzzle; rules: RuleInfo[]; ladderMin?: number; genre?: string; items?: Record<string, Partial<Record<ItemType, number>>>; forcedKana?: string }
+  | { type: 'room_state'; roomId: string; owner: string; settings: RoomSettings; players: PlayerInfo[]; scores: Record<string, number>; lives: Record<string, number>; maxLives: number; history: HistoryEntry[]; turnOrder: string[]; currentTurn: string; currentWord: string; status: string; match?: MatchSummary; daily?: DailyPuzzle; rules: RuleInfo[]; ladderMin?: number; genre?: string; items?: Record<string, Partial<Record<ItemType, number>>>; forcedKana?: string }
   | { type: 'player_joined'; player: string }
   | { type: 'player_left'; player: string }
   | { type: 'player_list'; players: string[] }
-  | { type: 'game_started'; currentWord: string; firstWord: string; turnOrder: string[]; currentTurn: string; lives: Record<string, number>; maxLives: number; timeLimit: number }
-  | { type: 'word_accepted'; word: string; player: string; scores: Record<string, number>; lives: Record<string, number>; currentTurn: string }
+  | { type: 'game_started'; currentWord: string; firstWord?: string; turnOrder: string[]; currentTurn: string; lives: Record<string, number>; maxLives: number; timeLimit: number; match?: MatchSummary; daily?: DailyPuzzle; rules: RuleInfo[]; ladderMin?: number; genre?: string; items?: Record<string, Partial<Record<ItemType, number>>> }
+  | { type: 'word_accepted'; word: string; player: string; scores: Record<string, number>; lives: Record<string, number>; currentTurn: string; ladderMin?: number; genre?: string; responseMs?: number; forcedKana?: string }
+  | { type: 'genre_changed'; genre: string; previous: string }
+  | { type: 'bomb_exploded'; player: string; currentTurn: string }
+  | { type: 'item_used'; player: string; item: ItemType; label: string; items: Record<string, Partial<Record<ItemType, number>>>; currentTurn: string; turnOrder: string[]; kana?: string; timeLeft?: number; forcedKana?: string }
+  | { type: 'item_rejected'; item: string; message: string }
   | { type: 'answer_rejected'; message: string }
   | { type: 'timer'; timeLeft: number }
-  | { type: 'game_over'; reason: string; winner?: string; loser?: string; scores: Record<string, number>; history: HistoryEntry[]; lives: Record<string, number>; resultId?: string }
+  | { type: 'game_over'; reason: string; winner?: string; loser?: string; scores: Record<string, number>; history: HistoryEntry[]; lives: Record<string, number>; resultId?: string; match?: MatchSummary; daily?: DailyClear }
+  | { type: 'rematch_update'; player: string; ready: string[]; totalPlayers: number }
   | { type: 'vote_request'; voteType: 'challenge' | 'genre'; word: string; player: string; challenger?: string; reason?: string; genre?: string; voteCount: number; totalPlayers: number }
   | { type: 'vote_update'; voteCount: number; totalPlayers: number }
-  | { type: 'vote_result'; accepted: boolean; word: string; message?: string; reverted?: boolean; currentWord?: string; history?: HistoryEntry[]; scores?: Record<string, number>; lives?: Record<string, number>; currentTurn?: string; penaltyPlayer?: string; penaltyLives?: number; eliminated?: boolean }
+  | { type: 'vote_result'; accepted: boolean; word: string; message?: string; reverted?: boolean; currentWord?: string; history?: HistoryEntry[]; scores?: Record<string, number>; lives?: Record<string, number>; currentTurn?: string; penaltyPlayer?: string; penaltyLives?: number; eliminated?: boolean; ladderMin?: number; genre?: string; forcedKana?: string }
   | { type: 'rebuttal'; player: string; rebuttal: string }
   | { type: 'challenge_withdrawn'; message?: string }
-  | { type: 'penalty'; player: string; lives: number; reason: string; eliminated: boolean; allLives: Record<string, number> }
+  | { type: 'penalty'; player: string; lives: number; reason: string; eliminated: boolean; allLives: Record<string, number>; ladderMin?: number }
   | { type: 'turn_update'; turnOrder: string[]; currentTurn: string; scores: Record<string, number>; lives: Record<string, number>; maxLives: number }
-  | { type: 'settings_updated'; settings: RoomSettings }
+  | { type: 'settings_updated'; settings: RoomSettings; rules: RuleInfo[] }
+  | { type: 'server_shutdown'; countdown: number; message: string }
+  | { type: 'room_closed'; message: string }
+  | { type: 'kicked'; message: string }
+  | { type: 'announcement'; message: string }
+  | { type: 'result_owner'; resultId: string; token: string; visibility: ResultVisibility }
   | { type: 'error'; message: string };
 
 // === Shared types ===
+// Set with POST /api/results/{id}/visibility and the result_owner token.
+export type ResultVisibility = 'public' | 'unlisted' | 'private';
+
 export interface RoomSettings {
   name: string;
   minLen: number;
@@ -48,6 +64,26 @@ export interface RoomSettings {
   allowedRows?: string[];
   noDakuten?: boolean;
   private?: boolean;
+  rounds?: number;
+  password?: string;
+  hasPassword?: boolean;
+  // Input only: removes the room's password on update_settings or start_game.
+  clearPassword?: boolean;
+  // How the first word is decided; the engine reports it as game_started.firstWord.
+  startMode?: 'owner' | 'random' | 'fixed' | 'kana';
+  startWord?: string;
+  // Unit of minLen/maxLen: characters (default), morae, or syllables (っ and ー not counted).
+  lengthUnit?: '' | 'mora' | 'syllable';
+  customRules?: CustomRule[];
+  chainMode?: '' | 'reverse' | 'two' | 'double';
+  ladder?: boolean;
+  race?: boolean;
+  bomb?: boolean;
+  passes?: number;
+  powerUps?: boolean;
+  genres?: string[];
+  genreEvery?: number;
+  genreOrder?: '' | 'random';
 }
 
 export interface RoomInfo {
@@ -65,7 +101,70 @@ export interface PlayerInfo {
   lives: number;
 }
 
+export interface RoundResult {
+  round: number;
+  winner: string;
+  loser: string;
+  resultId?: string;
+  scores: Record<string, number>;
+}
+
+export interface MatchSummary {
+  id: string;
+  bestOf: number;
+  round: number;
+  wins: Record<string, number>;
+  rounds: RoundResult[];
+  winner?: string;
+  over: boolean;
+  readyCount: number;
+}
+
+// Today's puzzle, also served with its leaderboard by GET /api/daily.
+export interface DailyPuzzle {
+  date: string;
+  startWord: string;
+  targetKana: string;
+  minWords: number;
+  settings: RoomSettings;
+}
+
+export interface DailyClear {
+  date: string;
+  words: number;
+  durationMs: number;
+  rank: number;
+}
+
+export interface DailyEntry {
+  rank: number;
+  player: string;
+  words: number;
+  durationMs: number;
+  resultId?: string;
+}
+
+// A room owner's CEL constraint; the word passes when expr is true.
+export interface CustomRule {
+  expr: string;
+  message?: string;
+  penalty?: boolean;
+}
+
+// An active word validation rule and what happens to a word that breaks it.
+// An item a player can use on their turn.
+export type ItemType = 'pass' | 'reverse' | 'force' | 'time';
+
+export interface RuleInfo {
+  id: string;
+  description: string;
+  outcome: 'reject' | 'penalty' | 'vote';
+}
+
 export interface HistoryEntry {
   word: string;
   player: string;
+  genre?: string;
+  responseMs?: number;
+  forced?: string;
 }
@@ -1,3 +1,5 @@
+import type { DailyPuzzle, ResultVisibility, RoomSettings } from '../types/messages';
+
 export function getRoomLink(roomId: string): string {
   const url = new URL(window.location.href);
   url.searchParams.set('room', roomId);
@@ -41,9 +43,14 @@ export function isAllowedChar(ch: string): boolean {
 }
 
 export function filterHiragana(value: string): string {
+  // Fold half-width kana and compose voiced marks, as the server does.
+  const normalized = value
+    .replace(/\u309B/g, '\u3099')
+    .replace(/\u309C/g, '\u309A')
+    .normalize('NFKC');
   let filtered = '';
-  for (let i = 0; i < value.length; i++) {
-    if (isAllowedChar(value[i])) filtered += value[i];
+  for (let i = 0; i < normalized.length; i++) {
+    if (isAllowedChar(normalized[i])) filtered += normalized[i];
   }
   return katakanaToHiragana(filtered);
 }
@@ -52,3 +59,46 @@ let toastId = 0;
 export function nextToastId(): number {
   return ++toastId;
 }
+
+// Owner tokens for saved results, kept so the owner can change a result's
+// visibility later, including from its /results/{id} page.
+const RESULT_TOKENS_KEY = 'shiritori-result-tokens';
+
+export function saveResultToken(resultId: string, token: string): void {
+  try {
+    const tokens = JSON.parse(localStorage.getItem(RESULT_TOKENS_KEY) || '{}');
+    tokens[resultId] = token;
+    localStorage.setItem(RESULT_TOKENS_KEY, JSON.stringify(tokens));
+  } catch {
+    // Storage full or disabled; the token still works for this game over.
+  }
+}
+
+export async function setResultVisibility(resultId: string, token: string, visibility: ResultVisibility): Promise<boolean> {
+  try {
+    const res = await fetch(`/api/results/${encodeURIComponent(resultId)}/visibility`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ token, visibility }),
+    });
+    return res.ok;
+  } catch {
+    return false;
+  }
+}
+
+// Name of the unit minLen and maxLen are counted in.
+export function lengthUnitName(unit?: RoomSettings['lengthUnit']): string {
+  if (unit === 'mora') return '音';
+  if (unit === 'syllable') return '音節';
+  return '文字';
+}
+
+export function formatDuration(ms: number): string {
+  const sec = Math.round(ms / 1000);
+  return sec >= 60 ? `${Math.floor(sec / 60)}分${sec % 60}秒` : `${sec}秒`;
+}
+
+export function dailyGoalText(p: DailyPuzzle): string {
+  return `「${p.startWord}」から始めて、${p.minWords}語以上つないだあと「${p.targetKana}」で終わる言葉を出せばクリア！`;
+}
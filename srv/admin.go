@@ -0,0 +1,283 @@
+package srv
+
+import (
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Identity headers set by the exe.dev proxy for authenticated users.
+// They are only trustworthy when the server is reached through exed.
+const (
+	headerExeDevUserID = "X-ExeDev-UserID"
+	headerExeDevEmail  = "X-ExeDev-Email"
+)
+
+// SetAdmins configures who may use the admin area. Entries are matched
+// against the exe.dev user ID or (case-insensitively) the email address.
+func (s *Server) SetAdmins(ids []string) {
+	admins := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id != "" {
+			admins[id] = true
+		}
+	}
+	s.admins = admins
+}
+
+// adminIdentity returns the identity of the requesting admin, or "" if the
+// request is not from a configured admin.
+func (s *Server) adminIdentity(r *http.Request) string {
+	for _, h := range []string{headerExeDevUserID, headerExeDevEmail} {
+		v := strings.ToLower(strings.TrimSpace(r.Header.Get(h)))
+		if v != "" && s.admins[v] {
+			return v
+		}
+	}
+	return ""
+}
+
+// requireAdmin wraps an admin handler. Non-admins get 403; state-changing
+// requests must carry a JSON body so plain cross-site form posts are refused.
+func (s *Server) requireAdmin(next func(w http.ResponseWriter, r *http.Request, admin string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin := s.adminIdentity(r)
+		if admin == "" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet {
+			ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if ct != "application/json" {
+				http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+		next(w, r, admin)
+	}
+}
+
+// AdminRoomInfo describes a room for the admin dashboard, including private rooms.
+type AdminRoomInfo struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Owner       string            `json:"owner"`
+	Status      string            `json:"status"`
+	Private     bool              `json:"private"`
+	HasPassword bool              `json:"hasPassword"`
+	ChainLength int               `json:"chainLength"`
+	Players     []AdminPlayerInfo `json:"players"`
+}
+
+// AdminPlayerInfo describes a player in a room for the admin dashboard.
+type AdminPlayerInfo struct {
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+	Lives int    `json:"lives"`
+}
+
+// adminRooms returns every room sorted by ID.
+func (s *Server) adminRooms() []AdminRoomInfo {
+	rooms := s.Rooms.AllRooms()
+	list := make([]AdminRoomInfo, 0, len(rooms))
+	for _, room := range rooms {
+		room.mu.Lock()
+		info := AdminRoomInfo{
+			ID:          room.ID,
+			Name:        room.Settings.Name,
+			Owner:       room.Owner,
+			Status:      room.Status,
+			Private:     room.Settings.Private,
+			HasPassword: room.Settings.HasPassword,
+			Players:     []AdminPlayerInfo{},
+		}
+		scores := room.getScoresLocked()
+		lives := room.getLivesLocked()
+		for name := range room.Players {
+			info.Players = append(info.Players, AdminPlayerInfo{Name: name, Score: scores[name], Lives: lives[name]})
+		}
+		if room.Engine != nil {
+			history, _, _, _ := room.Engine.Snapshot()
+			info.ChainLength = len(history)
+		}
+		room.mu.Unlock()
+		sort.Slice(info.Players, func(i, j int) bool { return info.Players[i].Name < info.Players[j].Name })
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// HandleAdminPage serves the admin dashboard.
+func (s *Server) HandleAdminPage(w http.ResponseWriter, r *http.Request, admin string) {
+	tmpl, err := template.ParseFS(templatesFS, "templates/admin.html")
+	if err != nil {
+		slog.Error("parse admin template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := struct {
+		Admin string
+		Rooms []AdminRoomInfo
+	}{admin, s.adminRooms()}
+	if err := tmpl.Execute(w, data); err != nil {
+		slog.Error("execute admin template", "error", err)
+	}
+}
+
+// HandleAdminRooms lists all rooms as JSON.
+func (s *Server) HandleAdminRooms(w http.ResponseWriter, r *http.Request, admin string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"rooms": s.adminRooms()})
+}
+
+// HandleAdminCloseRoom tells everyone in a room it was closed, disconnects
+// them and removes the room.
+func (s *Server) HandleAdminCloseRoom(w http.ResponseWriter, r *http.Request, admin string) {
+	room := s.Rooms.GetRoom(r.PathValue("id"))
+	if room == nil {
+		http.NotFound(w, r)
+		return
+	}
+	room.StopTimer()
+	room.mu.Lock()
+	room.Status = "finished"
+	room.mu.Unlock()
+	room.Broadcast(mustMarshal(map[string]any{
+		"type":    "room_closed",
+		"message": "このルームは管理者によって閉じられました",
+	}))
+	for _, name := range room.PlayerNames() {
+		s.disconnectPlayer(room, name, "room closed")
+	}
+	s.Rooms.RemoveRoom(room.ID)
+	slog.Info("admin closed room", "admin", admin, "roomId", room.ID)
+	writeAdminOK(w)
+}
+
+// HandleAdminKickPlayer removes a player from a room.
+func (s *Server) HandleAdminKickPlayer(w http.ResponseWriter, r *http.Request, admin string) {
+	room := s.Rooms.GetRoom(r.PathValue("id"))
+	if room == nil {
+		http.NotFound(w, r)
+		return
+	}
+	name := r.PathValue("player")
+	room.mu.Lock()
+	p, ok := room.Players[name]
+	room.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	select {
+	case p.Send <- mustMarshal(map[string]any{
+		"type":    "kicked",
+		"message": "管理者によってルームから退出させられました",
+	}):
+	default:
+		metrics.broadcastsDropped.Inc("direct")
+	}
+	s.disconnectPlayer(room, name, "kicked")
+	slog.Info("admin kicked player", "admin", admin, "roomId", room.ID, "player", name)
+	writeAdminOK(w)
+}
+
+// disconnectPlayer closes a player's WebSocket; the connection's read loop
+// then removes them from the room as if they had left. Players without a
+// connection are removed directly.
+func (s *Server) disconnectPlayer(room *Room, name, reason string) {
+	room.mu.Lock()
+	p, ok := room.Players[name]
+	room.mu.Unlock()
+	if !ok {
+		return
+	}
+	if p.Conn == nil {
+		room.RemovePlayer(name)
+		s.Rooms.UntrackPlayer(name)
+		return
+	}
+	p.Conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason),
+		time.Now().Add(writeWait))
+	p.Conn.Close()
+}
+
+// HandleAdminAnnounce broadcasts a server-wide announcement to every room.
+func (s *Server) HandleAdminAnnounce(w http.ResponseWriter, r *http.Request, admin string) {
+	var req struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Message) == "" {
+		http.Error(w, "message required", http.StatusBadRequest)
+		return
+	}
+	msg := mustMarshal(map[string]any{
+		"type":    "announcement",
+		"message": req.Message,
+	})
+	rooms := s.Rooms.AllRooms()
+	for _, room := range rooms {
+		room.Broadcast(msg)
+	}
+	slog.Info("admin announcement", "admin", admin, "rooms", len(rooms))
+	writeAdminOK(w)
+}
+
+// HandleAdminDeleteResult deletes a saved game result (e.g. an abusive one).
+func (s *Server) HandleAdminDeleteResult(w http.ResponseWriter, r *http.Request, admin string) {
+	id := r.PathValue("id")
+	n, err := s.deleteGameResult(id)
+	if err != nil {
+		slog.Error("delete result", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	slog.Info("admin deleted result", "admin", admin, "resultId", id)
+	writeAdminOK(w)
+}
+
+// deleteGameResult removes a result, its event log and its search index
+// entry, returning the number of results deleted.
+func (s *Server) deleteGameResult(id string) (int64, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM game_events WHERE result_id = ?`, id); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM game_results_fts WHERE result_id = ?`, id); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`DELETE FROM game_results WHERE id = ?`, id)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	s.ogpImages.Remove(id)
+	return n, nil
+}
+
+func writeAdminOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"ok": true})
+}
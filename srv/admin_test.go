@@ -0,0 +1,169 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newAdminTestServer(t *testing.T) *Server {
+	t.Helper()
+	server, err := New(filepath.Join(t.TempDir(), "test_admin.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	server.SetAdmins([]string{"admin-user", "Ops@Example.com"})
+	return server
+}
+
+func TestRequireAdmin(t *testing.T) {
+	server := newAdminTestServer(t)
+	handler := server.requireAdmin(server.HandleAdminRooms)
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"no identity", "", "", http.StatusForbidden},
+		{"non-admin user", headerExeDevUserID, "someone-else", http.StatusForbidden},
+		{"admin user id", headerExeDevUserID, "admin-user", http.StatusOK},
+		{"admin email case-insensitive", headerExeDevEmail, "ops@example.COM", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/admin/api/rooms", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRequireAdminRejectsFormPosts(t *testing.T) {
+	server := newAdminTestServer(t)
+	req := httptest.NewRequest("POST", "/admin/api/announce", strings.NewReader("message=hi"))
+	req.Header.Set(headerExeDevUserID, "admin-user")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	server.requireAdmin(server.HandleAdminAnnounce)(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415, got %d", rec.Code)
+	}
+}
+
+func TestAdminRoomsIncludesPrivateRooms(t *testing.T) {
+	server := newAdminTestServer(t)
+	room := server.Rooms.CreateRoom("priv01", RoomSettings{Name: "secret", Private: true})
+	room.Owner = "alice"
+	room.AddPlayer(&Player{Name: "alice", Send: make(chan []byte, 256)})
+
+	req := httptest.NewRequest("GET", "/admin/api/rooms", nil)
+	req.Header.Set(headerExeDevUserID, "admin-user")
+	rec := httptest.NewRecorder()
+	server.requireAdmin(server.HandleAdminRooms)(rec, req)
+
+	var body struct {
+		Rooms []AdminRoomInfo `json:"rooms"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Rooms) != 1 || body.Rooms[0].ID != "priv01" || !body.Rooms[0].Private {
+		t.Fatalf("expected private room in admin listing, got %+v", body.Rooms)
+	}
+	if len(body.Rooms[0].Players) != 1 || body.Rooms[0].Players[0].Name != "alice" {
+		t.Errorf("expected alice in players, got %+v", body.Rooms[0].Players)
+	}
+}
+
+func TestAdminActions(t *testing.T) {
+	server := newAdminTestServer(t)
+	room := server.Rooms.CreateRoom("act01", RoomSettings{Name: "act"})
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	bob := &Player{Name: "bob", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	room.AddPlayer(bob)
+	server.Rooms.TrackPlayer("alice", room.ID)
+	server.Rooms.TrackPlayer("bob", room.ID)
+
+	// Announcement reaches every player.
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/admin/api/announce", strings.NewReader(`{"message":"メンテナンス予定"}`))
+	req.Header.Set(headerExeDevUserID, "admin-user")
+	req.Header.Set("Content-Type", "application/json")
+	server.requireAdmin(server.HandleAdminAnnounce)(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("announce: expected 200, got %d", rec.Code)
+	}
+	var msg map[string]any
+	json.Unmarshal(<-alice.Send, &msg)
+	if msg["type"] != "announcement" || msg["message"] != "メンテナンス予定" {
+		t.Errorf("unexpected announcement message: %v", msg)
+	}
+	<-bob.Send
+
+	// Kicking a connection-less player removes them directly.
+	rec = serveAdmin(server, "POST", "/admin/api/rooms/{id}/kick/{player}", "/admin/api/rooms/act01/kick/bob", server.HandleAdminKickPlayer)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("kick: expected 200, got %d", rec.Code)
+	}
+	json.Unmarshal(<-bob.Send, &msg)
+	if msg["type"] != "kicked" {
+		t.Errorf("expected kicked message, got %v", msg)
+	}
+	if names := room.PlayerNames(); len(names) != 1 || names[0] != "alice" {
+		t.Errorf("expected only alice left, got %v", names)
+	}
+
+	// Closing a room notifies players and removes it.
+	rec = serveAdmin(server, "POST", "/admin/api/rooms/{id}/close", "/admin/api/rooms/act01/close", server.HandleAdminCloseRoom)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("close: expected 200, got %d", rec.Code)
+	}
+	json.Unmarshal(<-alice.Send, &msg)
+	if msg["type"] != "room_closed" {
+		t.Errorf("expected room_closed message, got %v", msg)
+	}
+	if server.Rooms.GetRoom("act01") != nil {
+		t.Error("expected room to be removed")
+	}
+
+	// Deleting a saved result.
+	id, err := server.saveGameResult(&GameResult{RoomName: "act"})
+	if err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+	rec = serveAdmin(server, "DELETE", "/admin/api/results/{id}", "/admin/api/results/"+id, server.HandleAdminDeleteResult)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d", rec.Code)
+	}
+	if _, err := server.loadResult(id); err == nil {
+		t.Error("expected result to be deleted")
+	}
+	rec = serveAdmin(server, "DELETE", "/admin/api/results/{id}", "/admin/api/results/"+id, server.HandleAdminDeleteResult)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for already-deleted result, got %d", rec.Code)
+	}
+}
+
+// serveAdmin routes a single admin request through a mux so path values are set.
+func serveAdmin(server *Server, method, pattern, path string, h func(http.ResponseWriter, *http.Request, string)) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc(method+" "+pattern, server.requireAdmin(h))
+	req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+	req.Header.Set(headerExeDevUserID, "admin-user")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
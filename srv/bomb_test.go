@@ -0,0 +1,105 @@
+package srv
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFuseTimer(t *testing.T) {
+	var explosions atomic.Int32
+	ft := NewFuseTimer(10*time.Millisecond, 20*time.Millisecond, func() { explosions.Add(1) })
+	ft.Start()
+	ft.Start() // already burning; must not light a second fuse
+	time.Sleep(75 * time.Millisecond)
+	ft.Stop()
+	time.Sleep(5 * time.Millisecond) // let an explosion already under way finish
+	n := explosions.Load()
+	if n < 2 {
+		t.Errorf("expected the fuse to relight after each explosion, got %d explosions", n)
+	}
+	if ft.Burning() {
+		t.Error("expected the fuse to be out after Stop")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if explosions.Load() != n {
+		t.Error("expected no explosions after Stop")
+	}
+}
+
+func TestExplode(t *testing.T) {
+	ge := NewGameEngine(RoomSettings{Bomb: true, MaxLives: 2}, []string{"alice", "bob", "carol"}, nil)
+	ge.ValidateAndSubmitWord("ねこ", "alice", false)
+	if holder := ge.Explode(); holder != "bob" || ge.Players["bob"].Lives != 1 {
+		t.Fatalf("expected bob to lose a life, got %q with %d lives", holder, ge.Players["bob"].Lives)
+	}
+	if ge.CurrentTurn() != "bob" {
+		t.Errorf("expected bob to keep the turn, got %q", ge.CurrentTurn())
+	}
+	ge.Explode()
+	if ge.CurrentTurn() != "carol" {
+		t.Errorf("expected an eliminated holder to pass the turn, got %q", ge.CurrentTurn())
+	}
+	if ev := ge.EventLog(); ev[len(ev)-1].Type != EventPenalty || ev[len(ev)-1].Reason != bombReason {
+		t.Errorf("expected the explosion in the event log, got %+v", ev[len(ev)-1])
+	}
+	if err := validateSettings(RoomSettings{Bomb: true, Race: true}); err == nil {
+		t.Error("expected bomb mode to be refused in a race")
+	}
+}
+
+func TestBombExplodesInRoom(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_bomb.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("bm01", RoomSettings{Name: "bomb", Bomb: true, TimeLimit: 30})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	room.Fuse = NewFuseTimer(20*time.Millisecond, 30*time.Millisecond, func() { server.explodeBomb(room) })
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	bob := &Player{Name: "bob", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	room.AddPlayer(bob)
+	server.handleStartGame(room)
+	// The fuse burns across turns rather than resetting with each word.
+	server.handleAnswer(room, "alice", "ねこ")
+	defer room.StopTimer()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		var msg map[string]any
+		select {
+		case raw := <-alice.Send:
+			json.Unmarshal(raw, &msg)
+		case <-deadline:
+			t.Fatal("expected the bomb to explode")
+		}
+		if msg["type"] == "timer" && msg["timeLeft"] != nil && msg["timeLeft"].(float64) < 29 {
+			t.Fatalf("expected timer messages to carry only the turn timer, got %v", msg)
+		}
+		if msg["type"] != "bomb_exploded" {
+			continue
+		}
+		if msg["player"] != "bob" || len(msg) != 3 {
+			t.Errorf("expected bob to hold the bomb and nothing else to be sent, got %v", msg)
+		}
+		break
+	}
+	if lives := room.Engine.GetPlayerLives("bob"); lives != defaultMaxLives-1 {
+		t.Errorf("expected bob to lose a life, got %d", lives)
+	}
+	state, _ := json.Marshal(room.GetState())
+	var decoded map[string]any
+	json.Unmarshal(state, &decoded)
+	for key := range decoded {
+		if key == "fuse" || key == "fuseLeft" || key == "bombLeft" {
+			t.Errorf("expected room_state not to reveal the fuse, got %s", key)
+		}
+	}
+	if decoded["timeLeft"].(float64) != 30 {
+		t.Errorf("expected timeLeft to be the turn timer, got %v", decoded["timeLeft"])
+	}
+}
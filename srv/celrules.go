@@ -0,0 +1,195 @@
+package srv
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+const (
+	// maxCustomRules is how many custom rules a room may define.
+	maxCustomRules = 5
+	// maxCustomRuleExprLen and maxCustomRuleMessageLen bound, in characters,
+	// what an owner may type for one rule.
+	maxCustomRuleExprLen    = 200
+	maxCustomRuleMessageLen = 60
+	// customRuleCostLimit caps the work one evaluation may do. It is well
+	// above what any sensible rule over a single word needs.
+	customRuleCostLimit = 10000
+)
+
+// CustomRule is a room owner's constraint written as a CEL expression over
+// the submitted word. The word passes when Expr evaluates to true. Variables:
+//
+//	word      string  the word as typed (normalized)
+//	hiragana  string  the word in hiragana
+//	length    int     length in the room's length unit
+//	first     string  first kana
+//	last      string  last kana, as the next word must start with it
+//	previous  string  hiragana of the previous word; "" for the first word
+//	turn      int     number of the word being played, from 1
+//	player    string  name of the player submitting it
+type CustomRule struct {
+	Expr    string `json:"expr"`
+	Message string `json:"message,omitempty"` // shown when the word breaks the rule
+	Penalty bool   `json:"penalty,omitempty"` // breaking it costs a life instead of a retry
+}
+
+var (
+	celEnvOnce sync.Once
+	celEnv     *cel.Env
+	celEnvErr  error
+)
+
+// customRuleTrials are the words a rule is evaluated against before it is
+// accepted, so a rule that can't be evaluated (division by zero, cost limit)
+// is refused up front rather than at play time.
+var customRuleTrials = []WordCheck{
+	{Word: "しりとり", Hiragana: "しりとり", Turn: 1, Player: "player"},
+	{Word: "リンゴ", Hiragana: "りんご", Previous: "しりとり", Turn: 2, Player: "player"},
+	{Word: "ゴールデンウィーク", Hiragana: "ごーるでんうぃーく", Previous: "りんご", Turn: 100, Player: "player"},
+}
+
+func customRuleEnv() (*cel.Env, error) {
+	celEnvOnce.Do(func() {
+		celEnv, celEnvErr = cel.NewEnv(
+			cel.Variable("word", cel.StringType),
+			cel.Variable("hiragana", cel.StringType),
+			cel.Variable("length", cel.IntType),
+			cel.Variable("first", cel.StringType),
+			cel.Variable("last", cel.StringType),
+			cel.Variable("previous", cel.StringType),
+			cel.Variable("turn", cel.IntType),
+			cel.Variable("player", cel.StringType),
+			ext.Strings(),
+		)
+	})
+	return celEnv, celEnvErr
+}
+
+// compileCustomRule parses and type-checks a rule expression.
+func compileCustomRule(expr string) (cel.Program, error) {
+	env, err := customRuleEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("結果が真偽値（true/false）になる式にしてください")
+	}
+	return env.Program(ast, cel.CostLimit(customRuleCostLimit))
+}
+
+// validateCustomRules checks a room's custom rules before they are accepted,
+// returning an error players can read.
+func validateCustomRules(rules []CustomRule) error {
+	if len(rules) > maxCustomRules {
+		return fmt.Errorf("カスタムルールは%d個までです", maxCustomRules)
+	}
+	for i, r := range rules {
+		if r.Expr == "" {
+			return fmt.Errorf("カスタムルール%d: 式を入力してください", i+1)
+		}
+		if utf8.RuneCountInString(r.Expr) > maxCustomRuleExprLen {
+			return fmt.Errorf("カスタムルール%d: 式は%d文字以内にしてください", i+1, maxCustomRuleExprLen)
+		}
+		if utf8.RuneCountInString(r.Message) > maxCustomRuleMessageLen {
+			return fmt.Errorf("カスタムルール%d: メッセージは%d文字以内にしてください", i+1, maxCustomRuleMessageLen)
+		}
+		prg, err := compileCustomRule(r.Expr)
+		if err != nil {
+			return fmt.Errorf("カスタムルール%d: %v", i+1, err)
+		}
+		for _, w := range customRuleTrials {
+			if _, _, err := prg.Eval(customRuleVars(w)); err != nil {
+				return fmt.Errorf("カスタムルール%d: 「%s」で評価できません: %v", i+1, w.Word, err)
+			}
+		}
+	}
+	return nil
+}
+
+// celRule is a compiled CustomRule.
+type celRule struct {
+	id   string
+	rule CustomRule
+	prg  cel.Program
+}
+
+// customRules compiles the settings' custom rules. Rules that no longer
+// compile (e.g. restored from an older snapshot) are skipped.
+func customRules(s RoomSettings) []Rule {
+	var rules []Rule
+	for i, r := range s.CustomRules {
+		prg, err := compileCustomRule(r.Expr)
+		if err != nil {
+			slog.Warn("skip custom rule", "expr", r.Expr, "error", err)
+			continue
+		}
+		rules = append(rules, celRule{id: fmt.Sprintf("custom-%d", i+1), rule: r, prg: prg})
+	}
+	return rules
+}
+
+// customRuleVars binds the variables a rule expression sees for a word.
+func customRuleVars(w WordCheck) map[string]any {
+	return map[string]any{
+		"word":     w.Word,
+		"hiragana": w.Hiragana,
+		"length":   wordLength(w.Hiragana, w.Settings.LengthUnit),
+		"first":    string(getFirstChar(w.Hiragana)),
+		"last":     string(getLastChar(w.Hiragana)),
+		"previous": w.Previous,
+		"turn":     w.Turn,
+		"player":   w.Player,
+	}
+}
+
+// customRuleInfos describes the settings' custom rules without compiling them.
+func customRuleInfos(s RoomSettings) []RuleInfo {
+	var infos []RuleInfo
+	for i, r := range s.CustomRules {
+		infos = append(infos, celRule{id: fmt.Sprintf("custom-%d", i+1), rule: r}.Info())
+	}
+	return infos
+}
+
+func (r celRule) Info() RuleInfo {
+	info := RuleInfo{ID: r.id, Description: r.rule.Message, Outcome: OutcomeReject}
+	if info.Description == "" {
+		info.Description = r.rule.Expr
+	}
+	if r.rule.Penalty {
+		info.Outcome = OutcomePenalty
+	}
+	return info
+}
+
+func (r celRule) Check(w WordCheck) (ValidateResult, string) {
+	out, _, err := r.prg.Eval(customRuleVars(w))
+	if err != nil {
+		// Trial evaluation catches most of these when the rule is saved.
+		// A word the rule can't judge is not let through, but it doesn't
+		// cost a life either.
+		slog.Warn("evaluate custom rule", "expr", r.rule.Expr, "error", err)
+		return ValidateRejected, fmt.Sprintf("ルール「%s」でこの言葉を判定できませんでした", r.Info().Description)
+	}
+	if ok, _ := out.Value().(bool); ok {
+		return ValidateOK, ""
+	}
+	msg := r.rule.Message
+	if msg == "" {
+		msg = fmt.Sprintf("ルール「%s」を満たしていません", r.rule.Expr)
+	}
+	if r.rule.Penalty {
+		return ValidatePenalty, msg
+	}
+	return ValidateRejected, msg
+}
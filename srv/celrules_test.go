@@ -0,0 +1,87 @@
+package srv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCustomRules(t *testing.T) {
+	if err := validateCustomRules([]CustomRule{
+		{Expr: "length >= turn / 5 + 2"},
+		{Expr: `!hiragana.contains("う")`},
+		{Expr: `previous == "" || first == previous.substring(previous.size() - 1)`},
+	}); err != nil {
+		t.Errorf("expected valid rules, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		rules []CustomRule
+		want  string
+	}{
+		{[]CustomRule{{Expr: "length >="}}, "カスタムルール1"},
+		{[]CustomRule{{Expr: "length"}}, "真偽値"},
+		{[]CustomRule{{Expr: "true"}, {Expr: "size == 3"}}, "カスタムルール2"},
+		{[]CustomRule{{Expr: `word == 1`}}, "カスタムルール1"},
+		{[]CustomRule{{Expr: strings.Repeat("a", maxCustomRuleExprLen+1)}}, "文字以内"},
+		{make([]CustomRule, maxCustomRules+1), "個まで"},
+		{[]CustomRule{{Expr: "length / (turn - 1) > 0"}}, "評価できません"},
+		{[]CustomRule{{Expr: costlyRule}}, "評価できません"},
+	} {
+		if err := validateCustomRules(tc.rules); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%+v: expected error containing %q, got %v", tc.rules, tc.want, err)
+		}
+	}
+}
+
+func TestCustomRulesInEngine(t *testing.T) {
+	settings := RoomSettings{CustomRules: []CustomRule{
+		{Expr: "length >= turn + 1", Message: "だんだん長く"},
+		{Expr: `!hiragana.contains("う")`, Penalty: true},
+	}}
+	infos := ruleInfos(settings)
+	if got := infos[len(infos)-2:]; got[0].ID != "custom-1" || got[0].Description != "だんだん長く" || got[1].Outcome != OutcomePenalty {
+		t.Fatalf("unexpected custom rule infos %+v", got)
+	}
+
+	ge := NewGameEngine(settings, []string{"alice", "bob"}, nil)
+	if res, _ := ge.ValidateAndSubmitWord("ねこ", "alice", false); res != ValidateOK {
+		t.Fatalf("expected ねこ to pass on turn 1, got %v", res)
+	}
+	if res, msg := ge.ValidateAndSubmitWord("こま", "bob", false); res != ValidateRejected || msg != "だんだん長く" {
+		t.Errorf("expected a 2-kana word to be rejected on turn 2, got %v %q", res, msg)
+	}
+	if res, msg := ge.ValidateAndSubmitWord("こうら", "bob", false); res != ValidatePenalty || !strings.Contains(msg, "hiragana.contains") {
+		t.Errorf("expected う to cost a life, got %v %q", res, msg)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("こあら", "bob", false); res != ValidateOK {
+		t.Errorf("expected こあら to pass, got %v", res)
+	}
+	if ge.Players["bob"].Lives != defaultMaxLives-1 {
+		t.Errorf("expected bob to lose one life, got %d", ge.Players["bob"].Lives)
+	}
+
+	// A rule that slips past validation and then fails to evaluate doesn't
+	// let the word through.
+	ge = NewGameEngine(RoomSettings{CustomRules: []CustomRule{{Expr: "length / (turn - 1) > 0"}}}, []string{"alice"}, nil)
+	if res, msg := ge.ValidateAndSubmitWord("ねこ", "alice", false); res != ValidateRejected || !strings.Contains(msg, "判定できません") {
+		t.Errorf("expected a failed evaluation to reject the word, got %v %q", res, msg)
+	}
+	if ge.Players["alice"].Lives != defaultMaxLives {
+		t.Errorf("expected a failed evaluation not to cost a life, got %d", ge.Players["alice"].Lives)
+	}
+}
+
+const costlyRule = `[1,2,3,4,5,6,7,8,9,10].all(a, [1,2,3,4,5,6,7,8,9,10].all(b, [1,2,3,4,5,6,7,8,9,10].all(c, [1,2,3,4,5,6,7,8,9,10].all(d, word.size() > 0))))`
+
+func TestCustomRuleCostLimit(t *testing.T) {
+	prg, err := compileCustomRule(costlyRule)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if _, _, err := prg.Eval(map[string]any{
+		"word": "ねこ", "hiragana": "ねこ", "length": 2, "first": "ね", "last": "こ",
+		"previous": "", "turn": 1, "player": "alice",
+	}); err == nil || !strings.Contains(err.Error(), "cost") {
+		t.Errorf("expected the cost limit to stop evaluation, got %v", err)
+	}
+}
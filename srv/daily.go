@@ -0,0 +1,259 @@
+package srv
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dailyZone is the time zone daily puzzles roll over in.
+var dailyZone = time.FixedZone("JST", 9*60*60)
+
+const (
+	// dailyLeaderboardSize is how many entries the daily API returns.
+	dailyLeaderboardSize = 20
+	// dailyTimeLimit and dailyMaxLives are the same for every puzzle.
+	dailyTimeLimit = 30
+	dailyMaxLives  = 3
+)
+
+// dailyExcludableRows are the rows a puzzle may forbid.
+var dailyExcludableRows = []string{"か行", "さ行", "た行", "は行", "ま行", "ら行"}
+
+// DailyPuzzle is the solo puzzle everyone plays on a given day: start from
+// StartWord and, after at least MinWords words, play a word ending in
+// TargetKana. Fewer words, then less time, ranks higher.
+type DailyPuzzle struct {
+	Date       string       `json:"date"`
+	StartWord  string       `json:"startWord"`
+	TargetKana string       `json:"targetKana"`
+	MinWords   int          `json:"minWords"`
+	Settings   RoomSettings `json:"settings"`
+}
+
+// DailyEntry is a player's best clear of a daily puzzle.
+type DailyEntry struct {
+	Rank       int    `json:"rank"`
+	Player     string `json:"player"`
+	Words      int    `json:"words"`
+	DurationMs int64  `json:"durationMs"`
+	ResultID   string `json:"resultId,omitempty"`
+}
+
+// dailyDate returns the puzzle date (YYYY-MM-DD) for t.
+func dailyDate(t time.Time) string {
+	return t.In(dailyZone).Format(time.DateOnly)
+}
+
+// NewDailyPuzzle generates the puzzle for a date. The same date always gives
+// the same puzzle.
+func NewDailyPuzzle(date string) DailyPuzzle {
+	sum := sha256.Sum256([]byte("shiritori-daily:" + date))
+	rng := rand.New(rand.NewPCG(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])))
+
+	start := startWords[rng.IntN(len(startWords))]
+	link := getLastChar(start)
+	p := DailyPuzzle{
+		Date:      date,
+		StartWord: start,
+		MinWords:  3 + rng.IntN(3),
+		Settings: RoomSettings{
+			Name:       "今日のしりとり " + date,
+			StartMode:  StartFixed,
+			StartWord:  start,
+			MinLen:     2,
+			TimeLimit:  dailyTimeLimit,
+			MaxLives:   dailyMaxLives,
+			MaxPlayers: 1,
+			Private:    true,
+		},
+	}
+
+	// Add one extra constraint, keeping the puzzle solvable from the start
+	// word's last kana.
+	var excluded string
+	switch rng.IntN(4) {
+	case 0:
+		p.Settings.MinLen = 3
+	case 1:
+		p.Settings.MaxLen = 4
+	case 2:
+		if !IsDakuten(link) && !IsHandakuten(link) {
+			p.Settings.NoDakuten = true
+		}
+	case 3:
+		var rows []string
+		for _, row := range dailyExcludableRows {
+			if GetKanaRow(link) != row {
+				rows = append(rows, row)
+			}
+		}
+		excluded = rows[rng.IntN(len(rows))]
+		for _, row := range GetKanaRowNames() {
+			if row != excluded {
+				p.Settings.AllowedRows = append(p.Settings.AllowedRows, row)
+			}
+		}
+	}
+
+	var targets []rune
+	for _, r := range startKana {
+		if r != link && GetKanaRow(r) != excluded {
+			targets = append(targets, r)
+		}
+	}
+	p.TargetKana = string(targets[rng.IntN(len(targets))])
+	return p
+}
+
+// clearedBy reports whether playing word as the n-th word clears the puzzle.
+func (p *DailyPuzzle) clearedBy(word string, n int) bool {
+	return n >= p.MinWords && string(getLastChar(toHiragana(word))) == p.TargetKana
+}
+
+// recordDailyResult keeps a player's best clear of a puzzle and returns
+// their rank on that day's leaderboard.
+func (s *Server) recordDailyResult(date string, entry DailyEntry) (int, error) {
+	_, err := s.DB.Exec(
+		`INSERT INTO daily_results (date, player, words, duration_ms, result_id, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (date, player) DO UPDATE SET
+		   words = excluded.words, duration_ms = excluded.duration_ms,
+		   result_id = excluded.result_id, created_at = excluded.created_at
+		 WHERE excluded.words < daily_results.words
+		    OR (excluded.words = daily_results.words AND excluded.duration_ms < daily_results.duration_ms)`,
+		date, entry.Player, entry.Words, entry.DurationMs, entry.ResultID, time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	var rank int
+	err = s.DB.QueryRow(
+		`SELECT COUNT(*) + 1 FROM daily_results d, daily_results me
+		 WHERE me.date = ? AND me.player = ? AND d.date = me.date
+		   AND (d.words < me.words OR (d.words = me.words AND d.duration_ms < me.duration_ms))`,
+		date, entry.Player,
+	).Scan(&rank)
+	return rank, err
+}
+
+// dailyLeaderboard returns the best clears of a puzzle. Result links are
+// only included for results their owner hasn't made private.
+func (s *Server) dailyLeaderboard(date string, limit int) ([]DailyEntry, error) {
+	rows, err := s.DB.Query(
+		`SELECT d.player, d.words, d.duration_ms, COALESCE(gr.id, '')
+		 FROM daily_results d
+		 LEFT JOIN game_results gr ON gr.id = d.result_id AND gr.visibility != ?
+		 WHERE d.date = ? ORDER BY d.words, d.duration_ms, d.created_at LIMIT ?`,
+		VisibilityPrivate, date, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	entries := []DailyEntry{}
+	for rows.Next() {
+		var e DailyEntry
+		if err := rows.Scan(&e.Player, &e.Words, &e.DurationMs, &e.ResultID); err != nil {
+			return nil, err
+		}
+		e.Rank = len(entries) + 1
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// HandleDaily returns a day's puzzle and leaderboard: today's by default, or
+// a past one with /api/daily/{date}. Future puzzles are not revealed.
+func (s *Server) HandleDaily(w http.ResponseWriter, r *http.Request) {
+	today := dailyDate(time.Now())
+	date := r.PathValue("date")
+	if date == "" {
+		date = today
+	}
+	if _, err := time.Parse(time.DateOnly, date); err != nil || date > today {
+		http.NotFound(w, r)
+		return
+	}
+	board, err := s.dailyLeaderboard(date, dailyLeaderboardSize)
+	if err != nil {
+		slog.Error("load daily leaderboard", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"puzzle":      NewDailyPuzzle(date),
+		"leaderboard": board,
+	})
+}
+
+// handleCreateDailyRoom creates a solo room for today's puzzle and starts it.
+func (s *Server) handleCreateDailyRoom(conn *websocket.Conn, name string) (*Room, *Player) {
+	puzzle := NewDailyPuzzle(dailyDate(time.Now()))
+	settings := puzzle.Settings
+	room, player := s.handleCreateRoom(conn, name, &settings)
+	room.mu.Lock()
+	room.Daily = &puzzle
+	room.mu.Unlock()
+	s.handleStartGame(room)
+	return room, player
+}
+
+// checkDailyCleared ends a daily game when its latest word clears the puzzle,
+// recording the clear on the leaderboard.
+func (s *Server) checkDailyCleared(room *Room, word, playerName string) {
+	room.mu.Lock()
+	puzzle := room.Daily
+	if puzzle == nil || room.Status != "playing" || room.Engine == nil {
+		room.mu.Unlock()
+		return
+	}
+	history, _, _, _ := room.Engine.Snapshot()
+	if !puzzle.clearedBy(word, len(history)) {
+		room.mu.Unlock()
+		return
+	}
+	room.Status = "finished"
+	duration := time.Since(room.StartedAt)
+	// StartedAt isn't kept across restarts; the event log is.
+	if events := room.Engine.EventLog(); len(events) > 0 && events[0].Type == EventStart {
+		duration = time.Since(events[0].Time)
+	}
+	scores := room.getScoresLocked()
+	lives := room.getLivesLocked()
+	room.mu.Unlock()
+	room.Votes.Clear()
+	room.StopTimer()
+
+	gameOverMsg := map[string]any{
+		"type":    "game_over",
+		"reason":  "クリア！",
+		"winner":  playerName,
+		"scores":  scores,
+		"history": history,
+		"lives":   lives,
+	}
+	if room.OnGameOver != nil {
+		gameOverMsg = room.OnGameOver(room, gameOverMsg)
+	}
+	entry := DailyEntry{Player: playerName, Words: len(history), DurationMs: duration.Milliseconds()}
+	entry.ResultID, _ = gameOverMsg["resultId"].(string)
+	rank, err := s.recordDailyResult(puzzle.Date, entry)
+	if err != nil {
+		slog.Error("record daily result", "error", err)
+	}
+	gameOverMsg["daily"] = map[string]any{
+		"date":       puzzle.Date,
+		"words":      entry.Words,
+		"durationMs": entry.DurationMs,
+		"rank":       rank,
+	}
+	room.Broadcast(mustMarshal(gameOverMsg))
+}
@@ -0,0 +1,146 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewDailyPuzzle(t *testing.T) {
+	if a, b := NewDailyPuzzle("2026-03-01"), NewDailyPuzzle("2026-03-01"); a.StartWord != b.StartWord || a.TargetKana != b.TargetKana || a.MinWords != b.MinWords {
+		t.Fatalf("expected the same puzzle for the same date, got %+v and %+v", a, b)
+	}
+
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, dailyZone)
+	starts := map[string]bool{}
+	for i := range 366 {
+		date := day.AddDate(0, 0, i).Format(time.DateOnly)
+		p := NewDailyPuzzle(date)
+		starts[p.StartWord] = true
+		link := getLastChar(p.StartWord)
+		target := []rune(p.TargetKana)[0]
+		if p.MinWords < 3 || p.MinWords > 5 || p.Settings.MaxPlayers != 1 || !p.Settings.Private {
+			t.Errorf("%s: unexpected puzzle %+v", date, p)
+		}
+		if target == link {
+			t.Errorf("%s: target %c is the start word's last kana", date, target)
+		}
+		// The chain must be able to continue from the start word and reach
+		// the target under the day's rules.
+		for _, r := range []rune{link, target} {
+			if p.Settings.NoDakuten && (IsDakuten(r) || IsHandakuten(r)) {
+				t.Errorf("%s: %c is ruled out by no-dakuten", date, r)
+			}
+			if len(p.Settings.AllowedRows) > 0 && !slices.Contains(p.Settings.AllowedRows, GetKanaRow(r)) {
+				t.Errorf("%s: %c is outside the allowed rows %v", date, r, p.Settings.AllowedRows)
+			}
+		}
+	}
+	if len(starts) < 20 {
+		t.Errorf("expected start words to vary across the year, got %d distinct", len(starts))
+	}
+}
+
+func TestDailyChallengeClear(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_daily.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	today := dailyDate(time.Now())
+	room := server.Rooms.CreateRoom("dy01", RoomSettings{Name: "daily", MinLen: 2, MaxPlayers: 1, StartMode: StartFixed, StartWord: "しりとり"})
+	room.Owner = "alice"
+	room.Daily = &DailyPuzzle{Date: today, StartWord: "しりとり", TargetKana: "ら", MinWords: 2}
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	server.handleStartGame(room)
+
+	if err := room.UpdateSettings(RoomSettings{MinLen: 1}); err == nil {
+		t.Error("expected daily rules to be locked")
+	}
+	if res, _ := room.ValidateAndSubmitWord("らっぱ", "alice"); res != ValidateRejected {
+		t.Errorf("expected the first word to continue from the start word, got %v", res)
+	}
+	if res, _ := room.ValidateAndSubmitWord("しりとり", "alice"); res != ValidateRejected {
+		t.Errorf("expected a word not starting with り to be rejected, got %v", res)
+	}
+	server.handleAnswer(room, "alice", "りら") // ends in ら, but too early
+	if room.Status != "playing" {
+		t.Fatal("expected the game to continue before MinWords")
+	}
+	server.handleAnswer(room, "alice", "らくだ")
+	server.handleAnswer(room, "alice", "だちょう")
+	server.handleAnswer(room, "alice", "うきわ")
+	server.handleAnswer(room, "alice", "わら")
+	if room.Status != "finished" {
+		t.Fatalf("expected the puzzle to be cleared, status %q", room.Status)
+	}
+
+	var started, over map[string]any
+	for len(alice.Send) > 0 {
+		var msg map[string]any
+		json.Unmarshal(<-alice.Send, &msg)
+		switch msg["type"] {
+		case "game_started":
+			started = msg
+		case "game_over":
+			over = msg
+		}
+	}
+	if started == nil || started["currentWord"] != "しりとり" || started["daily"] == nil {
+		t.Errorf("expected game_started to open on the start word, got %v", started)
+	}
+	daily, _ := over["daily"].(map[string]any)
+	if over["winner"] != "alice" || daily == nil || daily["words"] != float64(5) || daily["rank"] != float64(1) {
+		t.Fatalf("unexpected game_over %v", over)
+	}
+
+	if id, _ := over["resultId"].(string); id == "" {
+		t.Fatal("expected the clear to be saved as a result")
+	} else if _, err := server.DB.Exec(`UPDATE game_results SET visibility = ? WHERE id = ?`, VisibilityPrivate, id); err != nil {
+		t.Fatalf("hide result: %v", err)
+	}
+
+	// A worse clear doesn't replace the best one; others rank by words, then time.
+	if rank, err := server.recordDailyResult(today, DailyEntry{Player: "alice", Words: 9, DurationMs: 1}); err != nil || rank != 1 {
+		t.Errorf("expected alice to stay first, got %d (%v)", rank, err)
+	}
+	server.recordDailyResult(today, DailyEntry{Player: "bob", Words: 3, DurationMs: 90000})
+	server.recordDailyResult(today, DailyEntry{Player: "carol", Words: 3, DurationMs: 60000})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/daily", server.HandleDaily)
+	mux.HandleFunc("GET /api/daily/{date}", server.HandleDaily)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/daily", nil))
+	var resp struct {
+		Puzzle      DailyPuzzle  `json:"puzzle"`
+		Leaderboard []DailyEntry `json:"leaderboard"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode daily: %v", err)
+	}
+	var order []string
+	for _, e := range resp.Leaderboard {
+		order = append(order, e.Player)
+	}
+	if resp.Puzzle.Date != today || !slices.Equal(order, []string{"carol", "bob", "alice"}) {
+		t.Errorf("unexpected daily response %+v", resp)
+	}
+	if e := resp.Leaderboard[2]; e.Words != 5 || e.Rank != 3 || e.ResultID != "" {
+		t.Errorf("expected alice's private result to be unlinked, got %+v", e)
+	}
+
+	tomorrow := dailyDate(time.Now().AddDate(0, 0, 1))
+	for _, path := range []string{"/api/daily/" + tomorrow, "/api/daily/yesterday"} {
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, rec.Code)
+		}
+	}
+}
@@ -7,3 +7,8 @@ var templatesFS embed.FS
 
 //go:embed static/*
 var staticFS embed.FS
+
+// ogpFontTTF is M+ 1p Regular, used to draw Japanese text on PNG OGP images.
+//
+//go:embed fonts/mplus-1p-regular.ttf
+var ogpFontTTF []byte
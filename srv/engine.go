@@ -2,6 +2,7 @@ package srv
 
 import (
 	"fmt"
+	"maps"
 	"sync"
 	"time"
 )
@@ -11,6 +12,8 @@ const (
 	defaultMaxLives = 3
 	// voteTimeout is how long players have to vote before auto-resolution.
 	voteTimeout = 15 * time.Second
+	// bombReason is the penalty reason when bomb mode's fuse runs out.
+	bombReason = "💣 爆弾が爆発しました"
 )
 
 // GameEngine manages game state: word validation, turns, scores, and lives.
@@ -19,19 +22,30 @@ type GameEngine struct {
 	Settings    RoomSettings
 	History     []WordEntry
 	CurrentWord string
+	StartWord   string    // word (or kana) the game opened on; not part of History
+	LadderMin   int       // ladder mode: length the next word must reach
+	PromptAt    time.Time // when the current word was put to the players
+	Genre       string    // rotating genre mode: genre the next word must fit
+	ForcedKana  string    // force item: kana the next word must start with
+	QueuedKana  string    // force item: ForcedKana once the current player's word is played
 	UsedWords   map[string]bool
 	TurnOrder   []string
 	TurnIndex   int
 	Players     map[string]*PlayerState // game-level state per player
+	Events      []GameEvent             // append-only log for replays
+
+	// rules is the validation pipeline composed from Settings.
+	rules []Rule
 
 	// resetTimer is called after a word is applied to reset the turn timer.
 	resetTimer func()
 }
 
-// PlayerState holds per-player game state (score, lives).
+// PlayerState holds per-player game state (score, lives, items left).
 type PlayerState struct {
-	Score int
-	Lives int
+	Score int            `json:"score"`
+	Lives int            `json:"lives"`
+	Items map[string]int `json:"items,omitempty"` // see ItemPass etc.
 }
 
 // NewGameEngine creates a GameEngine from settings and player names.
@@ -43,30 +57,37 @@ func NewGameEngine(settings RoomSettings, turnOrder []string, resetTimer func())
 	}
 	players := make(map[string]*PlayerState, len(turnOrder))
 	for _, name := range turnOrder {
-		players[name] = &PlayerState{Score: 0, Lives: maxLives}
+		players[name] = &PlayerState{Score: 0, Lives: maxLives, Items: startingItems(settings)}
 	}
 	return &GameEngine{
 		Settings:    settings,
 		History:     []WordEntry{},
 		CurrentWord: "",
+		LadderMin:   ladderBase(settings),
+		Genre:       firstGenre(settings),
 		UsedWords:   make(map[string]bool),
 		TurnOrder:   turnOrder,
 		TurnIndex:   0,
 		Players:     players,
+		rules:       RulesFor(settings),
 		resetTimer:  resetTimer,
 	}
 }
 
-// AddPlayer adds a player mid-game with full lives.
+// AddPlayer adds a player mid-game with full lives. A player who is already
+// part of the game (e.g. reconnecting to a restored room) keeps their state.
 func (ge *GameEngine) AddPlayer(name string) {
 	ge.mu.Lock()
 	defer ge.mu.Unlock()
-	maxLives := ge.Settings.MaxLives
-	if maxLives <= 0 {
-		maxLives = defaultMaxLives
+	if _, ok := ge.Players[name]; !ok {
+		maxLives := ge.Settings.MaxLives
+		if maxLives <= 0 {
+			maxLives = defaultMaxLives
+		}
+		ge.Players[name] = &PlayerState{Score: 0, Lives: maxLives, Items: startingItems(ge.Settings)}
+		ge.TurnOrder = append(ge.TurnOrder, name)
 	}
-	ge.Players[name] = &PlayerState{Score: 0, Lives: maxLives}
-	ge.TurnOrder = append(ge.TurnOrder, name)
+	ge.recordLocked(GameEvent{Type: EventJoin, Actor: name, Player: name})
 }
 
 // RemovePlayer removes a player from the game engine.
@@ -83,6 +104,7 @@ func (ge *GameEngine) RemovePlayer(name string) {
 			break
 		}
 	}
+	ge.recordLocked(GameEvent{Type: EventLeave, Actor: name, Player: name})
 }
 
 // ValidateResult represents the outcome of word validation.
@@ -91,12 +113,20 @@ type ValidateResult int
 const (
 	ValidateOK       ValidateResult = iota // Word accepted
 	ValidateRejected                       // Word rejected (hard fail)
-	ValidateVote                           // Need genre vote
+	ValidateVote                           // Word needs a vote to be accepted
 	ValidatePenalty                        // Word rejected but player loses a life
 )
 
 // ValidateAndSubmitWord checks a word and applies it if valid.
 func (ge *GameEngine) ValidateAndSubmitWord(word, playerName string, hasVotePending bool) (ValidateResult, string) {
+	return ge.SubmitAnswer(word, playerName, -1, hasVotePending)
+}
+
+// SubmitAnswer is ValidateAndSubmitWord for an answer to a given prompt, the
+// number of words played when the player saw it. In race mode an answer to a
+// prompt someone else already answered is turned away without a penalty; a
+// negative prompt means the current one.
+func (ge *GameEngine) SubmitAnswer(word, playerName string, prompt int, hasVotePending bool) (ValidateResult, string) {
 	ge.mu.Lock()
 	defer ge.mu.Unlock()
 
@@ -104,8 +134,16 @@ func (ge *GameEngine) ValidateAndSubmitWord(word, playerName string, hasVotePend
 		return ValidateRejected, "投票中です。投票が終わるまでお待ちください"
 	}
 
-	// Check it's this player's turn
-	if len(ge.TurnOrder) > 0 && ge.TurnOrder[ge.TurnIndex] != playerName {
+	if ge.Settings.Race {
+		// Anyone still in the game may answer; the first valid answer wins.
+		if _, ok := ge.Players[playerName]; !ok {
+			return ValidateRejected, "ゲームに参加していません"
+		}
+		if prompt >= 0 && prompt != len(ge.History) {
+			return ValidateRejected, "ほかのプレイヤーが先に答えました"
+		}
+	} else if len(ge.TurnOrder) > 0 && ge.TurnOrder[ge.TurnIndex] != playerName {
+		// Check it's this player's turn
 		return ValidateRejected, fmt.Sprintf("%sさんの番です", ge.TurnOrder[ge.TurnIndex])
 	}
 
@@ -115,65 +153,50 @@ func (ge *GameEngine) ValidateAndSubmitWord(word, playerName string, hasVotePend
 	}
 
 	// Check that word is valid Japanese kana
+	word = normalizeWord(word)
 	if !isJapanese(word) {
 		return ValidateRejected, "ひらがな・カタカナで入力してください"
 	}
 
-	hiragana := toHiragana(word)
-
-	// Check length
-	wlen := charCount(hiragana)
-	if ge.Settings.MinLen > 0 && wlen < ge.Settings.MinLen {
-		return ValidateRejected, fmt.Sprintf("%d文字以上で入力してください", ge.Settings.MinLen)
-	}
-	if ge.Settings.MaxLen > 0 && wlen > ge.Settings.MaxLen {
-		return ValidateRejected, fmt.Sprintf("%d文字以下で入力してください", ge.Settings.MaxLen)
+	check := WordCheck{
+		Word:     word,
+		Hiragana: toHiragana(word),
+		Player:   playerName,
+		Turn:     len(ge.History) + 1,
+		MinLen:   ge.LadderMin,
+		Forced:   ge.ForcedKana,
+		Used:     ge.UsedWords,
+		Settings: ge.Settings,
 	}
-
-	// Check first char matches last char of current word (skip for first word)
 	if ge.CurrentWord != "" {
-		prevHiragana := toHiragana(ge.CurrentWord)
-		lastChar := getLastChar(prevHiragana)
-		firstChar := getFirstChar(hiragana)
-		if lastChar != firstChar {
-			return ValidateRejected, fmt.Sprintf("「%c」から始まる言葉を入力してください", lastChar)
-		}
-	}
-
-	// Check not already used — penalty
-	if ge.UsedWords[hiragana] {
-		ge.applyPenaltyLocked(playerName)
-		return ValidatePenalty, "この言葉はすでに使われています"
-	}
-
-	// Check ends with ん
-	runes := []rune(hiragana)
-	if runes[len(runes)-1] == 'ん' {
-		ge.applyPenaltyLocked(playerName)
-		return ValidatePenalty, "「ん」で終わる言葉を使いました"
-	}
-
-	// Check no dakuten/handakuten
-	if ge.Settings.NoDakuten {
-		if badChar := ValidateNoDakuten(hiragana); badChar != 0 {
-			ge.applyPenaltyLocked(playerName)
-			return ValidatePenalty, fmt.Sprintf("「%c」は濁音・半濁音の文字です（濁音・半濁音禁止ルール）", badChar)
-		}
-	}
-
-	// Check allowed rows
-	if len(ge.Settings.AllowedRows) > 0 {
-		if badChar, badRow := ValidateAllowedRows(hiragana, ge.Settings.AllowedRows); badChar != 0 {
-			ge.applyPenaltyLocked(playerName)
-			return ValidatePenalty, fmt.Sprintf("「%c」は%sの文字です（使用可能な行: %s）", badChar, badRow, formatAllowedRows(ge.Settings.AllowedRows))
+		check.Previous = toHiragana(ge.CurrentWord)
+	}
+	for _, rule := range ge.rules {
+		switch result, reason := rule.Check(check); result {
+		case ValidateOK:
+		case ValidatePenalty:
+			return ge.penalizeLocked(playerName, reason)
+		case ValidateRejected:
+			// In a race every wrong answer costs a life.
+			if ge.Settings.Race {
+				return ge.penalizeLocked(playerName, reason)
+			}
+			return result, reason
+		default:
+			return result, reason
 		}
 	}
 
 	// All good — apply the word
-	ge.applyWordLocked(word, hiragana, playerName)
+	ge.applyWordLocked(word, check.Hiragana, playerName)
 	return ValidateOK, ""
 }
 
+// RuleInfos describes the rules the engine validates words with.
+func (ge *GameEngine) RuleInfos() []RuleInfo {
+	return describeRules(ge.rules)
+}
+
 // ApplyWord applies an accepted word (used by vote resolution). Acquires lock.
 func (ge *GameEngine) ApplyWord(word, hiragana, playerName string) {
 	ge.mu.Lock()
@@ -184,31 +207,31 @@ func (ge *GameEngine) ApplyWord(word, hiragana, playerName string) {
 func (ge *GameEngine) applyWordLocked(word, hiragana, playerName string) {
 	ge.UsedWords[hiragana] = true
 	ge.CurrentWord = word
-	ge.History = append(ge.History, WordEntry{
+	ge.climbLadderLocked(hiragana)
+	now := time.Now()
+	entry := WordEntry{
 		Word:   word,
 		Player: playerName,
-		Time:   time.Now().Format(time.RFC3339),
-	})
+		Time:   now.Format(time.RFC3339),
+		Genre:  ge.Genre,
+		Forced: ge.ForcedKana,
+	}
+	ge.ForcedKana, ge.QueuedKana = ge.QueuedKana, ""
+	if ge.Settings.Race && !ge.PromptAt.IsZero() {
+		entry.ResponseMs = now.Sub(ge.PromptAt).Milliseconds()
+	}
+	ge.History = append(ge.History, entry)
+	ge.PromptAt = now
 
 	// Award point
 	if ps, ok := ge.Players[playerName]; ok {
 		ps.Score++
 	}
 
-	// Advance turn, skipping eliminated players
-	if len(ge.TurnOrder) > 0 {
-		start := ge.TurnIndex
-		for {
-			ge.TurnIndex = (ge.TurnIndex + 1) % len(ge.TurnOrder)
-			if ge.TurnIndex == start {
-				break
-			}
-			nextName := ge.TurnOrder[ge.TurnIndex]
-			if ps, ok := ge.Players[nextName]; ok && ps.Lives > 0 {
-				break
-			}
-		}
-	}
+	ge.advanceTurnLocked()
+
+	ge.recordLocked(GameEvent{Type: EventWord, Actor: playerName, Player: playerName, Word: word})
+	ge.rotateGenreLocked()
 
 	// Reset timer
 	if ge.resetTimer != nil {
@@ -216,17 +239,91 @@ func (ge *GameEngine) applyWordLocked(word, hiragana, playerName string) {
 	}
 }
 
-func (ge *GameEngine) applyPenaltyLocked(playerName string) {
+func (ge *GameEngine) applyPenaltyLocked(playerName, reason string) {
 	if ps, ok := ge.Players[playerName]; ok {
 		ps.Lives--
 	}
+	if ge.Settings.Ladder {
+		ge.LadderMin = ladderBase(ge.Settings)
+	}
+	ge.recordLocked(GameEvent{Type: EventPenalty, Actor: playerName, Player: playerName, Reason: reason})
+}
+
+// ladderBase is the length ladder mode starts from and falls back to.
+func ladderBase(s RoomSettings) int {
+	if !s.Ladder {
+		return 0
+	}
+	return max(s.MinLen, 1)
+}
+
+// climbLadderLocked raises the ladder minimum to one more than the length of
+// the word just played. Past MaxLen the ladder starts over.
+func (ge *GameEngine) climbLadderLocked(hiragana string) {
+	if !ge.Settings.Ladder {
+		return
+	}
+	ge.LadderMin = wordLength(hiragana, ge.Settings.LengthUnit) + 1
+	if ge.Settings.MaxLen > 0 && ge.LadderMin > ge.Settings.MaxLen {
+		ge.LadderMin = ladderBase(ge.Settings)
+	}
+}
+
+// MinLength returns the length the next word must reach in ladder mode, or 0.
+func (ge *GameEngine) MinLength() int {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	return ge.LadderMin
+}
+
+// advanceTurnLocked passes the turn on, skipping eliminated players. Caller
+// must hold ge.mu.
+func (ge *GameEngine) advanceTurnLocked() {
+	if len(ge.TurnOrder) == 0 {
+		return
+	}
+	start := ge.TurnIndex
+	for {
+		ge.TurnIndex = (ge.TurnIndex + 1) % len(ge.TurnOrder)
+		if ge.TurnIndex == start {
+			break
+		}
+		nextName := ge.TurnOrder[ge.TurnIndex]
+		if ps, ok := ge.Players[nextName]; ok && ps.Lives > 0 {
+			break
+		}
+	}
+}
+
+// Explode costs whoever holds the turn a life when bomb mode's fuse runs out,
+// and returns their name ("" if nobody holds it). A player the bomb eliminates
+// passes the turn on. Acquires lock.
+func (ge *GameEngine) Explode() string {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	if ge.Settings.Race || len(ge.TurnOrder) == 0 || ge.TurnIndex >= len(ge.TurnOrder) {
+		return ""
+	}
+	holder := ge.TurnOrder[ge.TurnIndex]
+	ge.applyPenaltyLocked(holder, bombReason)
+	if ps, ok := ge.Players[holder]; ok && ps.Lives <= 0 {
+		ge.advanceTurnLocked()
+	}
+	return holder
+}
+
+// penalizeLocked applies a penalty for a rejected word and returns the
+// matching validation result.
+func (ge *GameEngine) penalizeLocked(playerName, reason string) (ValidateResult, string) {
+	ge.applyPenaltyLocked(playerName, reason)
+	return ValidatePenalty, reason
 }
 
 // ApplyPenalty decrements a player's lives. Acquires lock.
-func (ge *GameEngine) ApplyPenalty(playerName string) {
+func (ge *GameEngine) ApplyPenalty(playerName, reason string) {
 	ge.mu.Lock()
 	defer ge.mu.Unlock()
-	ge.applyPenaltyLocked(playerName)
+	ge.applyPenaltyLocked(playerName, reason)
 }
 
 // RevertWord reverts the last word (used when a challenge is upheld).
@@ -234,7 +331,9 @@ func (ge *GameEngine) RevertWord(word, playerName string) {
 	ge.mu.Lock()
 	defer ge.mu.Unlock()
 
+	var reverted WordEntry
 	if len(ge.History) > 0 {
+		reverted = ge.History[len(ge.History)-1]
 		ge.History = ge.History[:len(ge.History)-1]
 	}
 	delete(ge.UsedWords, toHiragana(word))
@@ -246,7 +345,7 @@ func (ge *GameEngine) RevertWord(word, playerName string) {
 		}
 	}
 
-	prevWord := ""
+	prevWord := ge.StartWord
 	if len(ge.History) > 0 {
 		prevWord = ge.History[len(ge.History)-1].Word
 	}
@@ -259,15 +358,57 @@ func (ge *GameEngine) RevertWord(word, playerName string) {
 		}
 	}
 
+	ge.recordLocked(GameEvent{Type: EventRevert, Player: playerName, Word: word})
+
+	// Undo a genre change the reverted word brought about
+	if reverted.Genre != "" {
+		ge.setGenreLocked(reverted.Genre)
+	}
+
+	// The word's player gets back any force item they used on it
+	ge.QueuedKana, ge.ForcedKana = ge.ForcedKana, reverted.Forced
+
 	// Penalize
-	ge.applyPenaltyLocked(playerName)
+	ge.applyPenaltyLocked(playerName, "指摘により単語が取り消されました")
 
 	ge.CurrentWord = prevWord
+	ge.PromptAt = time.Now()
 	if ge.resetTimer != nil {
 		ge.resetTimer()
 	}
 }
 
+// Open applies the start mode before the first turn and returns the word the
+// game opens on, or "" if the first player may choose freely. An opening kana
+// is returned as a one-character word and, unlike a word, isn't marked used.
+func (ge *GameEngine) Open() string {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	ge.PromptAt = time.Now()
+	switch ge.Settings.StartMode {
+	case StartRandom:
+		ge.openLocked(randomStartWord(ge.Settings), true)
+	case StartFixed:
+		word := normalizeWord(ge.Settings.StartWord)
+		if !validStartWord(word) {
+			word = defaultStartWord
+		}
+		ge.openLocked(word, true)
+	case StartKana:
+		ge.openLocked(string(randomStartKana(ge.Settings)), false)
+	}
+	return ge.StartWord
+}
+
+func (ge *GameEngine) openLocked(word string, used bool) {
+	ge.StartWord = word
+	ge.CurrentWord = word
+	ge.climbLadderLocked(toHiragana(word))
+	if used {
+		ge.UsedWords[toHiragana(word)] = true
+	}
+}
+
 // GetAlivePlayers returns names of players with lives > 0.
 func (ge *GameEngine) GetAlivePlayers() []string {
 	ge.mu.Lock()
@@ -310,6 +451,20 @@ func (ge *GameEngine) CheckElimination(playerName string, totalPlayers int) (eli
 	return
 }
 
+// topScorer returns the player with the highest score, or "" on a tie.
+func topScorer(scores map[string]int) string {
+	best, top := -1, ""
+	for name, score := range scores {
+		switch {
+		case score > best:
+			best, top = score, name
+		case score == best:
+			top = ""
+		}
+	}
+	return top
+}
+
 // GetScores returns a map of player name -> score.
 func (ge *GameEngine) GetScores() map[string]int {
 	ge.mu.Lock()
@@ -351,10 +506,14 @@ func (ge *GameEngine) MaxLives() int {
 	return maxLives
 }
 
-// CurrentTurn returns the name of the player whose turn it is.
+// CurrentTurn returns the name of the player whose turn it is, or "" in race
+// mode, where everyone answers at once.
 func (ge *GameEngine) CurrentTurn() string {
 	ge.mu.Lock()
 	defer ge.mu.Unlock()
+	if ge.Settings.Race {
+		return ""
+	}
 	if len(ge.TurnOrder) > 0 && ge.TurnIndex < len(ge.TurnOrder) {
 		return ge.TurnOrder[ge.TurnIndex]
 	}
@@ -373,3 +532,81 @@ func (ge *GameEngine) Snapshot() (history []WordEntry, currentWord string, turnO
 	turnIndex = ge.TurnIndex
 	return
 }
+
+// GameSnapshot is the serializable state of a GameEngine.
+type GameSnapshot struct {
+	History     []WordEntry            `json:"history"`
+	CurrentWord string                 `json:"currentWord"`
+	StartWord   string                 `json:"startWord,omitempty"`
+	LadderMin   int                    `json:"ladderMin,omitempty"`
+	Genre       string                 `json:"genre,omitempty"`
+	ForcedKana  string                 `json:"forcedKana,omitempty"`
+	QueuedKana  string                 `json:"queuedKana,omitempty"`
+	UsedWords   []string               `json:"usedWords"`
+	TurnOrder   []string               `json:"turnOrder"`
+	TurnIndex   int                    `json:"turnIndex"`
+	Players     map[string]PlayerState `json:"players"`
+	Events      []GameEvent            `json:"events,omitempty"`
+}
+
+// ExportState returns a deep copy of the engine state for persistence.
+func (ge *GameEngine) ExportState() GameSnapshot {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	snap := GameSnapshot{
+		History:     make([]WordEntry, len(ge.History)),
+		CurrentWord: ge.CurrentWord,
+		StartWord:   ge.StartWord,
+		LadderMin:   ge.LadderMin,
+		Genre:       ge.Genre,
+		ForcedKana:  ge.ForcedKana,
+		QueuedKana:  ge.QueuedKana,
+		UsedWords:   make([]string, 0, len(ge.UsedWords)),
+		TurnOrder:   make([]string, len(ge.TurnOrder)),
+		TurnIndex:   ge.TurnIndex,
+		Players:     make(map[string]PlayerState, len(ge.Players)),
+		Events:      make([]GameEvent, len(ge.Events)),
+	}
+	copy(snap.History, ge.History)
+	copy(snap.Events, ge.Events)
+	for w := range ge.UsedWords {
+		snap.UsedWords = append(snap.UsedWords, w)
+	}
+	copy(snap.TurnOrder, ge.TurnOrder)
+	for name, ps := range ge.Players {
+		cp := *ps
+		cp.Items = maps.Clone(ps.Items)
+		snap.Players[name] = cp
+	}
+	return snap
+}
+
+// RestoreGameEngine rebuilds a GameEngine from a persisted snapshot.
+func RestoreGameEngine(settings RoomSettings, snap GameSnapshot, resetTimer func()) *GameEngine {
+	ge := NewGameEngine(settings, snap.TurnOrder, resetTimer)
+	if snap.History != nil {
+		ge.History = snap.History
+	}
+	ge.CurrentWord = snap.CurrentWord
+	ge.StartWord = snap.StartWord
+	if snap.LadderMin > 0 {
+		ge.LadderMin = snap.LadderMin
+	}
+	if snap.Genre != "" {
+		ge.Genre = snap.Genre
+	}
+	ge.ForcedKana = snap.ForcedKana
+	ge.QueuedKana = snap.QueuedKana
+	ge.Events = snap.Events
+	for _, w := range snap.UsedWords {
+		ge.UsedWords[w] = true
+	}
+	if snap.TurnIndex >= 0 && snap.TurnIndex < len(ge.TurnOrder) {
+		ge.TurnIndex = snap.TurnIndex
+	}
+	for name, ps := range snap.Players {
+		ps := ps
+		ge.Players[name] = &ps
+	}
+	return ge
+}
@@ -0,0 +1,198 @@
+package srv
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// Game event types recorded in the engine's event log.
+const (
+	EventStart      = "start"       // game started; TurnOrder is set, and Word if it opened on a start word
+	EventWord       = "word"        // word accepted
+	EventPenalty    = "penalty"     // player lost a life; Reason says why
+	EventChallenge  = "challenge"   // Challenger disputed Player's Word
+	EventRebuttal   = "rebuttal"    // challenged Player argued back; Text is set
+	EventWithdraw   = "withdraw"    // Challenger withdrew the challenge
+	EventVote       = "vote"        // Actor voted; Accepted is set
+	EventVoteResult = "vote_result" // vote resolved; Accepted and the tally are set
+	EventRevert     = "revert"      // Word was taken back after a challenge
+	EventTimeout    = "timeout"     // Player ran out of time
+	EventJoin       = "join"        // Player joined (or rejoined) mid-game
+	EventLeave      = "leave"       // Player left mid-game
+	EventEnd        = "end"         // game over; Player is the winner
+	EventGenre      = "genre"       // the rotating genre changed to Genre
+	EventItem       = "item"        // Player used Item; Text is a forced kana, TurnOrder a reversed order
+)
+
+// GameEvent is one entry in a game's event log. Actor is whoever caused the
+// event and is empty for events the server triggers (vote results, timeouts,
+// game end). Each event also carries the scores, lives and current turn after
+// it was applied, so a replay can render any point of the game without
+// re-running the rules.
+type GameEvent struct {
+	Seq        int            `json:"seq"`
+	Type       string         `json:"type"`
+	Time       time.Time      `json:"time"`
+	Actor      string         `json:"actor,omitempty"`
+	Player     string         `json:"player,omitempty"`
+	Word       string         `json:"word,omitempty"`
+	Reason     string         `json:"reason,omitempty"`
+	Challenger string         `json:"challenger,omitempty"`
+	VoteType   string         `json:"voteType,omitempty"`
+	Accepted   *bool          `json:"accepted,omitempty"`
+	Accepts    int            `json:"accepts,omitempty"`
+	Rejects    int            `json:"rejects,omitempty"`
+	Text       string         `json:"text,omitempty"`
+	Genre      string         `json:"genre,omitempty"`
+	Item       string         `json:"item,omitempty"`
+	TurnOrder  []string       `json:"turnOrder,omitempty"`
+	Turn       string         `json:"turn,omitempty"`
+	Scores     map[string]int `json:"scores"`
+	Lives      map[string]int `json:"lives"`
+}
+
+// RecordEvent appends an event to the log. Acquires lock.
+func (ge *GameEngine) RecordEvent(ev GameEvent) {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	ge.recordLocked(ev)
+}
+
+// recordLocked stamps the event with its sequence number, the time and the
+// current game state and appends it. Caller must hold ge.mu.
+func (ge *GameEngine) recordLocked(ev GameEvent) {
+	ev.Seq = len(ge.Events) + 1
+	ev.Time = time.Now().UTC()
+	if len(ge.TurnOrder) > 0 && ge.TurnIndex < len(ge.TurnOrder) {
+		ev.Turn = ge.TurnOrder[ge.TurnIndex]
+	}
+	ev.Scores = make(map[string]int, len(ge.Players))
+	ev.Lives = make(map[string]int, len(ge.Players))
+	for name, ps := range ge.Players {
+		ev.Scores[name] = ps.Score
+		ev.Lives[name] = ps.Lives
+	}
+	ge.Events = append(ge.Events, ev)
+}
+
+// EventLog returns a copy of the event log.
+func (ge *GameEngine) EventLog() []GameEvent {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	events := make([]GameEvent, len(ge.Events))
+	copy(events, ge.Events)
+	return events
+}
+
+// eventsFromHistory builds a word-only event log for results saved before
+// games recorded their events, so they can still be replayed.
+func eventsFromHistory(history []WordEntry) []GameEvent {
+	events := make([]GameEvent, 0, len(history))
+	scores := make(map[string]int)
+	for i, h := range history {
+		t, _ := time.Parse(time.RFC3339, h.Time)
+		scores[h.Player]++
+		snapshot := make(map[string]int, len(scores))
+		for name, n := range scores {
+			snapshot[name] = n
+		}
+		events = append(events, GameEvent{
+			Seq:    i + 1,
+			Type:   EventWord,
+			Time:   t,
+			Actor:  h.Player,
+			Player: h.Player,
+			Word:   h.Word,
+			Scores: snapshot,
+		})
+	}
+	return events
+}
+
+// insertGameEvents writes a game's event log as part of saving its result.
+func insertGameEvents(tx *sql.Tx, resultID string, events []GameEvent) error {
+	for _, ev := range events {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("marshal event %d: %w", ev.Seq, err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO game_events (result_id, seq, type, actor, event_json, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			resultID, ev.Seq, ev.Type, ev.Actor, string(data), ev.Time,
+		); err != nil {
+			return fmt.Errorf("insert event %d: %w", ev.Seq, err)
+		}
+	}
+	return nil
+}
+
+// loadGameEvents returns the saved event log for a result, in order.
+func (s *Server) loadGameEvents(resultID string) ([]GameEvent, error) {
+	rows, err := s.DB.Query(
+		`SELECT event_json FROM game_events WHERE result_id = ? ORDER BY seq`, resultID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []GameEvent
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		var ev GameEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return nil, fmt.Errorf("unmarshal event: %w", err)
+		}
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+// wantsNDJSON reports whether the client asked for newline-delimited JSON,
+// via ?format=ndjson or the Accept header.
+func wantsNDJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "ndjson" {
+		return true
+	}
+	accept, _, _ := mime.ParseMediaType(r.Header.Get("Accept"))
+	return accept == "application/x-ndjson"
+}
+
+// HandleGameEvents serves a saved game's event log as a JSON array, or as
+// NDJSON (one event per line) when requested.
+func (s *Server) HandleGameEvents(w http.ResponseWriter, r *http.Request) {
+	res, err := s.loadVisibleResult(r, r.PathValue("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("load game events", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	events := res.Events
+
+	if wantsNDJSON(r) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, ev := range events {
+			enc.Encode(ev)
+		}
+		return
+	}
+	if events == nil {
+		events = []GameEvent{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
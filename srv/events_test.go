@@ -0,0 +1,100 @@
+package srv
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestEventLogRecordsVotesAndRebuttals(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_events.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("ev01", RoomSettings{Name: "events", MinLen: 1})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	for _, name := range []string{"alice", "bob"} {
+		room.AddPlayer(&Player{Name: name, Send: make(chan []byte, 256)})
+	}
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	room.AddPlayer(&Player{Name: "carol", Send: make(chan []byte, 256)})
+
+	server.handleAnswer(room, "alice", "しりとり")
+	server.handleChallenge(room, "bob")
+	server.handleRebuttal(room, "alice", "辞書に載っています")
+	server.handleVote(room, "alice", true) // challenged player cannot vote
+	server.handleVote(room, "carol", true)
+
+	events := room.Engine.EventLog()
+	var types []string
+	for _, ev := range events {
+		types = append(types, ev.Type)
+	}
+	want := []string{EventStart, EventJoin, EventWord, EventChallenge, EventRebuttal, EventVote, EventVoteResult, EventRevert, EventPenalty}
+	if len(types) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, types)
+		}
+		if events[i].Seq != i+1 {
+			t.Errorf("event %d has seq %d", i, events[i].Seq)
+		}
+	}
+	if events[3].Actor != "bob" || events[4].Text != "辞書に載っています" || events[5].Actor != "carol" {
+		t.Errorf("unexpected actors or text: %+v %+v %+v", events[3], events[4], events[5])
+	}
+	if res := events[6]; res.Accepts != 1 || res.Rejects != 1 || res.Actor != "" {
+		t.Errorf("expected a 1-1 tally from the server, got %+v", res)
+	}
+
+	id, err := server.saveGameResult(&GameResult{RoomName: "events", Events: events})
+	if err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/results/{id}/events", server.HandleGameEvents)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/results/"+id+"/events", nil))
+	var got []GameEvent
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode events: %v", err)
+	}
+	if len(got) != len(events) || got[2].Word != "しりとり" {
+		t.Errorf("expected %d events from JSON endpoint, got %+v", len(events), got)
+	}
+
+	req := httptest.NewRequest("GET", "/api/results/"+id+"/events", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("expected NDJSON content type, got %q", ct)
+	}
+	lines := 0
+	sc := bufio.NewScanner(rec.Body)
+	for sc.Scan() {
+		var ev GameEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("line %d is not an event: %v", lines+1, err)
+		}
+		lines++
+	}
+	if lines != len(events) {
+		t.Errorf("expected %d NDJSON lines, got %d", len(events), lines)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/results/missing/events", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown result, got %d", rec.Code)
+	}
+}
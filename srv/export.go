@@ -0,0 +1,264 @@
+package srv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats for game results.
+const (
+	ExportCSV      = "csv"
+	ExportJSON     = "json"
+	ExportMarkdown = "md"
+)
+
+var exportContentTypes = map[string]string{
+	ExportCSV:      "text/csv; charset=utf-8",
+	ExportJSON:     "application/json",
+	ExportMarkdown: "text/markdown; charset=utf-8",
+}
+
+// exportFormat reads ?format=, defaulting to JSON.
+func exportFormat(r *http.Request) (string, bool) {
+	f := r.URL.Query().Get("format")
+	if f == "" {
+		f = ExportJSON
+	}
+	_, ok := exportContentTypes[f]
+	return f, ok
+}
+
+// csvHeader is the header row of CSV exports. There is one row per word, so a
+// result's details repeat on each of its rows.
+var csvHeader = []string{
+	"result_id", "created_at", "room_name", "genre", "winner", "reason",
+	"index", "word", "reading", "player", "timestamp", "think_seconds",
+}
+
+// writeResultCSV writes one row per word of a result. Results without words
+// still get a row so they are not lost from archives.
+func writeResultCSV(cw *csv.Writer, res *GameResult) error {
+	head := []string{
+		res.ID, res.CreatedAt.UTC().Format(time.RFC3339), res.RoomName,
+		res.Genre, res.Winner, res.Reason,
+	}
+	steps := chainSteps(res)
+	if len(steps) == 0 {
+		return cw.Write(append(head, "", "", "", "", "", ""))
+	}
+	for i, st := range steps {
+		row := append(head[:len(head):len(head)],
+			strconv.Itoa(i+1),
+			st.Word,
+			toHiragana(st.Word),
+			st.Player,
+			res.History[i].Time,
+			strconv.FormatFloat(st.Think.Seconds(), 'f', -1, 64),
+		)
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mdEsc keeps user text from breaking Markdown tables.
+func mdEsc(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// writeResultMarkdown writes a result as a Markdown section.
+func writeResultMarkdown(w io.Writer, res *GameResult) {
+	fmt.Fprintf(w, "# %s\n\n", mdEsc(res.RoomName))
+	fmt.Fprintf(w, "- ID: %s\n", res.ID)
+	fmt.Fprintf(w, "- 日時: %s\n", res.CreatedAt.UTC().Format(time.RFC3339))
+	if res.Genre != "" && res.Genre != "なし" {
+		fmt.Fprintf(w, "- ジャンル: %s\n", mdEsc(res.Genre))
+	}
+	if res.Winner != "" {
+		fmt.Fprintf(w, "- 勝者: %s\n", mdEsc(res.Winner))
+	}
+	if res.Reason != "" {
+		fmt.Fprintf(w, "- 終了理由: %s\n", mdEsc(res.Reason))
+	}
+
+	names := make([]string, 0, len(res.Scores))
+	for name := range res.Scores {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if res.Scores[names[i]] != res.Scores[names[j]] {
+			return res.Scores[names[i]] > res.Scores[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	fmt.Fprint(w, "\n## スコア\n\n| 順位 | プレイヤー | 点数 |\n| ---: | --- | ---: |\n")
+	for i, name := range names {
+		fmt.Fprintf(w, "| %d | %s | %d |\n", i+1, mdEsc(name), res.Scores[name])
+	}
+
+	fmt.Fprint(w, "\n## 単語\n\n")
+	steps := chainSteps(res)
+	if len(steps) == 0 {
+		fmt.Fprint(w, "（なし）\n")
+		return
+	}
+	fmt.Fprint(w, "| # | 単語 | 読み | プレイヤー | 時刻 | 考えた時間 |\n| ---: | --- | --- | --- | --- | ---: |\n")
+	for i, st := range steps {
+		fmt.Fprintf(w, "| %d | %s | %s | %s | %s | %s秒 |\n",
+			i+1, mdEsc(st.Word), mdEsc(toHiragana(st.Word)), mdEsc(st.Player),
+			res.History[i].Time, strconv.FormatFloat(st.Think.Seconds(), 'f', -1, 64))
+	}
+}
+
+// writeResults writes results in an export format. A single result is
+// written as a JSON object rather than an array.
+func writeResults(w io.Writer, format string, results []*GameResult, single bool) error {
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, res := range results {
+			if err := writeResultCSV(cw, res); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case ExportMarkdown:
+		for i, res := range results {
+			if i > 0 {
+				fmt.Fprint(w, "\n---\n\n")
+			}
+			writeResultMarkdown(w, res)
+		}
+		return nil
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if single {
+			return enc.Encode(results[0])
+		}
+		return enc.Encode(results)
+	}
+}
+
+func setExportHeaders(w http.ResponseWriter, format, name string) {
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+}
+
+// HandleExportResult downloads one result as CSV, JSON or Markdown.
+func (s *Server) HandleExportResult(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(r)
+	if !ok {
+		http.Error(w, "format must be csv, json or md", http.StatusBadRequest)
+		return
+	}
+	id := r.PathValue("id")
+	res, err := s.loadVisibleResult(r, id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	setExportHeaders(w, format, "shiritori-"+id)
+	if err := writeResults(w, format, []*GameResult{res}, true); err != nil {
+		slog.Error("export result", "error", err)
+	}
+}
+
+// parseExportDate accepts a date (YYYY-MM-DD, UTC) or an RFC 3339 time.
+func parseExportDate(s string) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, s)
+	return t, false, err
+}
+
+// resultIDsBetween lists results created in [from, to), oldest first.
+func (s *Server) resultIDsBetween(from, to time.Time) ([]string, error) {
+	rows, err := s.DB.Query(
+		`SELECT id FROM game_results WHERE created_at >= ? AND created_at < ? ORDER BY created_at, id`,
+		from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// HandleAdminExportResults downloads every result created in a date range,
+// whatever its visibility. ?from and ?to are inclusive dates (or RFC 3339
+// times); either may be omitted.
+func (s *Server) HandleAdminExportResults(w http.ResponseWriter, r *http.Request, admin string) {
+	format, ok := exportFormat(r)
+	if !ok {
+		http.Error(w, "format must be csv, json or md", http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	from, to := time.Unix(0, 0), time.Now().Add(time.Minute)
+	if v := q.Get("from"); v != "" {
+		t, _, err := parseExportDate(v)
+		if err != nil {
+			http.Error(w, "invalid from date", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if v := q.Get("to"); v != "" {
+		t, dateOnly, err := parseExportDate(v)
+		if err != nil {
+			http.Error(w, "invalid to date", http.StatusBadRequest)
+			return
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = t
+	}
+
+	ids, err := s.resultIDsBetween(from, to)
+	if err != nil {
+		slog.Error("list results for export", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	results := make([]*GameResult, 0, len(ids))
+	for _, id := range ids {
+		res, err := s.loadResult(id)
+		if err != nil {
+			slog.Error("load result for export", "id", id, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		results = append(results, res)
+	}
+	slog.Info("admin exported results", "admin", admin, "count", len(results), "format", format)
+	setExportHeaders(w, format, "shiritori-results")
+	if err := writeResults(w, format, results, false); err != nil {
+		slog.Error("export results", "error", err)
+	}
+}
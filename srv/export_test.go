@@ -0,0 +1,111 @@
+package srv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportResult(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_export.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	res := vizTestResult()
+	res.History[1].Word = "リンゴ"
+	res.RoomName = "a|b"
+	id, err := server.saveGameResult(res)
+	if err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/results/{id}/export", server.HandleExportResult)
+	export := func(format string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/results/"+id+"/export?format="+format, nil))
+		return rec
+	}
+
+	rec := export("csv")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("expected CSV, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "shiritori-"+id+".csv") {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(rows) != 4 || rows[0][7] != "word" {
+		t.Fatalf("expected header and 3 word rows, got %v", rows)
+	}
+	if row := rows[2]; row[6] != "2" || row[7] != "リンゴ" || row[8] != "りんご" || row[9] != "bob" || row[11] != "4" {
+		t.Errorf("unexpected word row %v", row)
+	}
+
+	var got GameResult
+	if err := json.NewDecoder(export("json").Body).Decode(&got); err != nil || got.ID != id || len(got.History) != 3 {
+		t.Errorf("expected JSON result %s, got %+v (%v)", id, got, err)
+	}
+
+	md := export("md").Body.String()
+	for _, want := range []string{`# a\|b`, "| 1 | alice | 2 |", "| 3 | ごりら | ごりら | alice |", "31秒"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in markdown:\n%s", want, md)
+		}
+	}
+
+	if rec := export("xml"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected unknown format to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestAdminBulkExport(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_export.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	server.SetAdmins([]string{"admin-user"})
+	var ids []string
+	for _, vis := range []string{VisibilityPublic, VisibilityPrivate} {
+		id, err := server.saveGameResult(&GameResult{RoomName: "bulk", Visibility: vis})
+		if err != nil {
+			t.Fatalf("save result: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	old := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := server.DB.Exec(`UPDATE game_results SET created_at = ? WHERE id = ?`, old, ids[0]); err != nil {
+		t.Fatalf("backdate result: %v", err)
+	}
+
+	exported := func(query string) []GameResult {
+		rec := serveAdmin(server, "GET", "/admin/api/results/export", "/admin/api/results/export"+query, server.HandleAdminExportResults)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("export %s: got %d", query, rec.Code)
+		}
+		var got []GameResult
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("decode export: %v", err)
+		}
+		return got
+	}
+	if got := exported(""); len(got) != 2 || got[0].ID != ids[0] {
+		t.Errorf("expected all results oldest first, got %+v", got)
+	}
+	if got := exported("?from=2020-05-01&to=2020-05-01"); len(got) != 1 || got[0].ID != ids[0] {
+		t.Errorf("expected inclusive date range to match the backdated result, got %+v", got)
+	}
+	if got := exported("?from=2021-01-01"); len(got) != 1 || got[0].ID != ids[1] || got[0].Visibility != VisibilityPrivate {
+		t.Errorf("expected admins to export private results, got %+v", got)
+	}
+	if rec := serveAdmin(server, "GET", "/admin/api/results/export", "/admin/api/results/export?to=yesterday", server.HandleAdminExportResults); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid date to be rejected, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package srv
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	// fuseMin and fuseMax bound a bomb mode fuse; each fuse burns for a
+	// random time in between.
+	fuseMin = 20 * time.Second
+	fuseMax = 60 * time.Second
+)
+
+// FuseTimer is bomb mode's hidden fuse. Unlike TimerManager it does not reset
+// when a word is played and never reports the time left: it burns across
+// turns, calls onExploded when it runs out, and relights with a new random
+// length until it is stopped.
+type FuseTimer struct {
+	mu         sync.Mutex
+	min, max   time.Duration
+	cancel     chan struct{}
+	onExploded func()
+}
+
+// NewFuseTimer creates a fuse burning for between min and max each time.
+// onExploded is called whenever it runs out.
+func NewFuseTimer(min, max time.Duration, onExploded func()) *FuseTimer {
+	return &FuseTimer{min: min, max: max, onExploded: onExploded}
+}
+
+// Start lights the fuse unless it is already burning.
+func (ft *FuseTimer) Start() {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if ft.cancel != nil {
+		return
+	}
+	ft.cancel = make(chan struct{})
+	go ft.run(ft.cancel)
+}
+
+// Stop puts the fuse out.
+func (ft *FuseTimer) Stop() {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if ft.cancel != nil {
+		close(ft.cancel)
+		ft.cancel = nil
+	}
+}
+
+// Burning reports whether the fuse is lit.
+func (ft *FuseTimer) Burning() bool {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	return ft.cancel != nil
+}
+
+// length picks how long the next fuse burns.
+func (ft *FuseTimer) length() time.Duration {
+	if ft.max <= ft.min {
+		return ft.min
+	}
+	return ft.min + rand.N(ft.max-ft.min)
+}
+
+// run burns fuse after fuse until cancel is closed.
+func (ft *FuseTimer) run(cancel chan struct{}) {
+	for {
+		t := time.NewTimer(ft.length())
+		select {
+		case <-cancel:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		ft.mu.Lock()
+		select {
+		case <-cancel:
+			ft.mu.Unlock()
+			return
+		default:
+		}
+		ft.mu.Unlock()
+		if ft.onExploded != nil {
+			ft.onExploded()
+		}
+	}
+}
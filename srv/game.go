@@ -6,6 +6,7 @@ import (
 	"math/rand/v2"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -22,16 +23,33 @@ const (
 
 // RoomSettings holds configuration for a game room.
 type RoomSettings struct {
-	Name        string   `json:"name"`
-	MinLen      int      `json:"minLen"`
-	MaxLen      int      `json:"maxLen"`
-	Genre       string   `json:"genre"`
-	TimeLimit   int      `json:"timeLimit"`
-	AllowedRows []string `json:"allowedRows,omitempty"` // e.g. ["あ行","か行"]; empty = all rows allowed
-	NoDakuten   bool     `json:"noDakuten,omitempty"`   // disallow dakuten/handakuten characters
-	MaxLives    int      `json:"maxLives"`              // max lives per player (default 3 if 0)
-	MaxPlayers  int      `json:"maxPlayers,omitempty"`   // max players per room (default 8 if 0)
-	Private     bool     `json:"private,omitempty"`      // if true, room is hidden from lobby list
+	Name          string       `json:"name"`
+	MinLen        int          `json:"minLen"`
+	MaxLen        int          `json:"maxLen"`
+	Genre         string       `json:"genre"`
+	TimeLimit     int          `json:"timeLimit"`
+	AllowedRows   []string     `json:"allowedRows,omitempty"`   // e.g. ["あ行","か行"]; empty = all rows allowed
+	NoDakuten     bool         `json:"noDakuten,omitempty"`     // disallow dakuten/handakuten characters
+	MaxLives      int          `json:"maxLives"`                // max lives per player (default 3 if 0)
+	MaxPlayers    int          `json:"maxPlayers,omitempty"`    // max players per room (default 8 if 0)
+	Private       bool         `json:"private,omitempty"`       // if true, room is hidden from lobby list
+	Rounds        int          `json:"rounds,omitempty"`        // best-of-N match length (default 1 if 0)
+	Password      string       `json:"password,omitempty"`      // plaintext on input only; hashed and cleared by the room
+	HasPassword   bool         `json:"hasPassword,omitempty"`   // true if joining requires a password
+	ClearPassword bool         `json:"clearPassword,omitempty"` // input only: remove the room's password
+	StartMode     string       `json:"startMode,omitempty"`     // how the first word is decided; see StartOwner etc.
+	StartWord     string       `json:"startWord,omitempty"`     // opening word for StartFixed
+	LengthUnit    string       `json:"lengthUnit,omitempty"`    // how MinLen/MaxLen measure words; see LengthChars etc.
+	CustomRules   []CustomRule `json:"customRules,omitempty"`   // owner-written CEL constraints
+	ChainMode     string       `json:"chainMode,omitempty"`     // how words link; see ChainNormal etc.
+	Ladder        bool         `json:"ladder,omitempty"`        // each word must be longer than the last; resets on a penalty
+	Genres        []string     `json:"genres,omitempty"`        // genres to rotate through; overrides Genre when set
+	GenreEvery    int          `json:"genreEvery,omitempty"`    // accepted words per genre (default 1 if 0)
+	GenreOrder    string       `json:"genreOrder,omitempty"`    // see GenreSequential etc.
+	Race          bool         `json:"race,omitempty"`          // everyone answers each word at once; first valid answer wins
+	Bomb          bool         `json:"bomb,omitempty"`          // a hidden fuse burns across turns; its holder loses a life when it runs out
+	Passes        int          `json:"passes,omitempty"`        // pass tokens each player starts with
+	PowerUps      bool         `json:"powerUps,omitempty"`      // each player starts with one of each power-up item
 }
 
 // WordEntry records a word played in the game.
@@ -39,6 +57,10 @@ type WordEntry struct {
 	Word   string `json:"word"`
 	Player string `json:"player"`
 	Time   string `json:"time"`
+	Genre  string `json:"genre,omitempty"`  // rotating genre the word was played under
+	Forced string `json:"forced,omitempty"` // kana a force item made the word start with
+	// ResponseMs is how long the word took to answer (race mode only).
+	ResponseMs int64 `json:"responseMs,omitempty"`
 }
 
 // Player represents a connected player.
@@ -62,16 +84,31 @@ type Room struct {
 	// Composed managers
 	Engine *GameEngine
 	Timer  *TimerManager
+	Fuse   *FuseTimer // bomb mode only
 	Votes  *VoteManager
 
+	// Match tracks cumulative round results; replaced when a new match starts.
+	Match *MatchState
+
+	// Daily is the puzzle this room plays; nil for ordinary rooms.
+	Daily *DailyPuzzle
+
+	// StartedAt is when the current (or last) game started.
+	StartedAt time.Time
+
 	// Callback for saving game result on game over (set by Server)
 	OnGameOver func(room *Room, result map[string]any) map[string]any
 
 	// EmptySince tracks when the room became empty; nil if room has players.
 	EmptySince *time.Time
-}
 
+	// password is the hashed join password; nil if the room has none.
+	password *roomPassword
 
+	// resumeTimeLeft is the turn time left when a playing room was restored
+	// from a snapshot; the timer resumes once a player rejoins.
+	resumeTimeLeft int
+}
 
 // RoomManager manages all active rooms.
 type RoomManager struct {
@@ -80,7 +117,10 @@ type RoomManager struct {
 	// playerRoom tracks which room each player name is currently in.
 	playerRoom map[string]string // player name -> room ID
 	// done is used to stop the cleanup goroutine.
-	done chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	// draining is set during shutdown; no new rooms may be created.
+	draining atomic.Bool
 }
 
 // NewRoomManager creates a new RoomManager.
@@ -109,9 +149,34 @@ func (rm *RoomManager) StartCleanup(interval, maxEmptyAge time.Duration) {
 	}()
 }
 
-// StopCleanup stops the background cleanup goroutine.
+// StopCleanup stops the background cleanup goroutine. Safe to call more than once.
 func (rm *RoomManager) StopCleanup() {
-	close(rm.done)
+	rm.stopOnce.Do(func() { close(rm.done) })
+}
+
+// SetDraining marks the manager as shutting down (or not).
+func (rm *RoomManager) SetDraining(draining bool) {
+	rm.draining.Store(draining)
+}
+
+// Draining reports whether the server is shutting down and refusing new rooms.
+func (rm *RoomManager) Draining() bool {
+	return rm.draining.Load()
+}
+
+// PlayingCount returns the number of rooms with a game in progress.
+func (rm *RoomManager) PlayingCount() int {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	n := 0
+	for _, r := range rm.rooms {
+		r.mu.Lock()
+		if r.Status == "playing" {
+			n++
+		}
+		r.mu.Unlock()
+	}
+	return n
 }
 
 // cleanupEmptyRooms removes rooms that have been empty longer than maxAge.
@@ -154,15 +219,17 @@ func (rm *RoomManager) PlayerRoomID(name string) string {
 
 // CreateRoom creates a new room with the given settings.
 func (rm *RoomManager) CreateRoom(id string, settings RoomSettings) *Room {
+	pw := readPassword(id, &settings)
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 
 	room := &Room{
-		ID:       id,
-		Settings: settings,
-		Players:  make(map[string]*Player),
-		Status:   "waiting",
+		ID:      id,
+		Players: make(map[string]*Player),
+		Status:  "waiting",
 	}
+	room.applyPasswordLocked(&settings, pw)
+	room.Settings = settings
 	rm.rooms[id] = room
 	return room
 }
@@ -174,6 +241,24 @@ func (rm *RoomManager) GetRoom(id string) *Room {
 	return rm.rooms[id]
 }
 
+// AddRoom registers an existing room (e.g. one restored from a snapshot).
+func (rm *RoomManager) AddRoom(room *Room) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.rooms[room.ID] = room
+}
+
+// AllRooms returns every active room, including private ones.
+func (rm *RoomManager) AllRooms() []*Room {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	rooms := make([]*Room, 0, len(rm.rooms))
+	for _, r := range rm.rooms {
+		rooms = append(rooms, r)
+	}
+	return rooms
+}
+
 // RemoveRoom removes a room by ID.
 func (rm *RoomManager) RemoveRoom(id string) {
 	rm.mu.Lock()
@@ -256,12 +341,7 @@ func (r *Room) AddPlayer(p *Player) {
 func (r *Room) PlayerNames() []string {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-
-	names := make([]string, 0, len(r.Players))
-	for name := range r.Players {
-		names = append(names, name)
-	}
-	return names
+	return r.playerNamesLocked()
 }
 
 // RemovePlayer removes a player from the room and returns the remaining count.
@@ -275,6 +355,9 @@ func (r *Room) RemovePlayer(name string) int {
 	if r.Engine != nil {
 		r.Engine.RemovePlayer(name)
 	}
+	if r.Match != nil {
+		r.Match.ClearReady(name)
+	}
 	return len(r.Players)
 }
 
@@ -283,13 +366,7 @@ func (r *Room) Broadcast(msg []byte) {
 	// Caller should NOT hold r.mu — we lock it here.
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	for _, p := range r.Players {
-		select {
-		case p.Send <- msg:
-		default:
-			// drop if channel full
-		}
-	}
+	r.broadcastLocked(msg)
 }
 
 // broadcastLocked sends a message to all players; caller MUST already hold r.mu.
@@ -298,11 +375,17 @@ func (r *Room) broadcastLocked(msg []byte) {
 		select {
 		case p.Send <- msg:
 		default:
+			// drop if channel full
+			metrics.broadcastsDropped.Inc("broadcast")
 		}
 	}
 }
 
-// StartGame begins the game. The room owner goes first and picks any word.
+// StartGame begins the game. In a match with rounds left, the loser of the
+// previous round goes first if still in the room; otherwise the room owner
+// does. The first word follows the room's start mode.
+// A new match is started unless the current one still has rounds to play;
+// those start only once every player has confirmed the rematch.
 func (r *Room) StartGame() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -310,6 +393,9 @@ func (r *Room) StartGame() error {
 	if r.Status == "playing" {
 		return fmt.Errorf("game already in progress")
 	}
+	if err := r.nextRoundLocked(); err != nil {
+		return err
+	}
 	if len(r.Players) < 1 {
 		return fmt.Errorf("need at least 1 player")
 	}
@@ -317,20 +403,34 @@ func (r *Room) StartGame() error {
 	if r.Timer != nil {
 		r.Timer.Stop()
 	}
+	if r.Fuse != nil {
+		r.Fuse.Stop()
+	}
 
 	r.Status = "playing"
+	r.StartedAt = time.Now()
+
+	// The previous round's loser only opens the next round of the same match.
+	first := r.Owner
+	if r.Match == nil || r.Match.Over() {
+		r.Match = NewMatchState(r.Settings.Rounds)
+	} else if loser := r.Match.LastLoser(); loser != "" {
+		if _, ok := r.Players[loser]; ok {
+			first = loser
+		}
+	}
 
-	// Build turn order with owner first, rest shuffled
+	// Build turn order with the first player first, rest shuffled
 	turnOrder := make([]string, 0, len(r.Players))
 	for name := range r.Players {
-		if name != r.Owner {
+		if name != first {
 			turnOrder = append(turnOrder, name)
 		}
 	}
 	rand.Shuffle(len(turnOrder), func(i, j int) {
 		turnOrder[i], turnOrder[j] = turnOrder[j], turnOrder[i]
 	})
-	turnOrder = append([]string{r.Owner}, turnOrder...)
+	turnOrder = append([]string{first}, turnOrder...)
 
 	// Create game engine
 	resetTimer := func() {
@@ -339,6 +439,8 @@ func (r *Room) StartGame() error {
 		}
 	}
 	r.Engine = NewGameEngine(r.Settings, turnOrder, resetTimer)
+	firstWord := r.Engine.Open()
+	r.Engine.RecordEvent(GameEvent{Type: EventStart, TurnOrder: turnOrder, Word: firstWord})
 
 	// Sync player connection-level state
 	for name, p := range r.Players {
@@ -356,28 +458,192 @@ func (r *Room) StartGame() error {
 	if r.Settings.TimeLimit > 0 && r.Timer != nil {
 		r.Timer.Start(r.Settings.TimeLimit)
 	}
+	if r.Settings.Bomb && r.Fuse != nil {
+		r.Fuse.Start()
+	}
 
 	return nil
 }
 
 // UpdateSettings updates the room settings. Only allowed when game is not playing.
 func (r *Room) UpdateSettings(s RoomSettings) error {
+	pw := readPassword(r.ID, &s)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if r.Status == "playing" {
 		return fmt.Errorf("ゲーム中は設定を変更できません")
 	}
+	if r.Daily != nil {
+		return fmt.Errorf("デイリーチャレンジのルールは変更できません")
+	}
+	if err := validateSettings(s); err != nil {
+		return err
+	}
+	// The match length is fixed once its first round is played.
+	if r.Match != nil && r.Match.Between() && s.Rounds != r.Settings.Rounds {
+		return fmt.Errorf("マッチの途中でラウンド数は変更できません")
+	}
 	// Preserve room name and private flag from original settings if not provided
 	if s.Name == "" {
 		s.Name = r.Settings.Name
 	}
+	r.applyPasswordLocked(&s, pw)
 	r.Settings = s
 	return nil
 }
 
+// validateSettings checks the parts of room settings that can be rejected
+// outright, returning an error players can read.
+func validateSettings(s RoomSettings) error {
+	if err := validateCustomRules(s.CustomRules); err != nil {
+		return err
+	}
+	if s.Bomb && s.Race {
+		return fmt.Errorf("爆弾モードと早い者勝ちモードは同時に使えません")
+	}
+	if err := validateItems(s); err != nil {
+		return err
+	}
+	if s.StartMode == StartFixed && s.StartWord != "" && !validStartWord(normalizeWord(s.StartWord)) {
+		return fmt.Errorf("最初のことば「%s」は使えません（ひらがな・カタカナで、「ん」で終わらない言葉にしてください）", s.StartWord)
+	}
+	return validateGenres(s)
+}
+
+// passwordChange is a join password change read from submitted settings.
+type passwordChange struct {
+	changed bool
+	hashed  *roomPassword // nil when the password is removed or hashing failed
+}
+
+// readPassword takes the password change out of s, clearing the plaintext so
+// it is never echoed to clients. A new password is hashed here, before any
+// lock is taken, as hashing is slow. An empty password with ClearPassword
+// unset keeps the room's current one.
+func readPassword(roomID string, s *RoomSettings) passwordChange {
+	pw, clear := s.Password, s.ClearPassword
+	s.Password, s.ClearPassword = "", false
+	switch {
+	case pw != "":
+		s.HasPassword = true
+		hashed, err := hashRoomPassword(pw)
+		if err != nil {
+			// Fail closed: the room stays locked but no password will match.
+			slog.Error("hash room password", "roomId", roomID, "error", err)
+		}
+		return passwordChange{changed: true, hashed: hashed}
+	case clear:
+		s.HasPassword = false
+		return passwordChange{changed: true}
+	}
+	return passwordChange{}
+}
+
+// applyPasswordLocked stores a password change read by readPassword.
+// Caller must hold r.mu.
+func (r *Room) applyPasswordLocked(s *RoomSettings, pw passwordChange) {
+	if !pw.changed {
+		s.HasPassword = r.Settings.HasPassword
+		return
+	}
+	r.password = pw.hashed
+}
+
+// CheckPassword reports whether password unlocks the room.
+// Rooms without a password accept any input.
+func (r *Room) CheckPassword(password string) bool {
+	r.mu.Lock()
+	locked, hashed := r.Settings.HasPassword, r.password
+	r.mu.Unlock()
+	if !locked {
+		return true
+	}
+	if hashed == nil {
+		return false
+	}
+	return hashed.matches(password)
+}
+
+// SetRematchReady marks a player as ready for the next round after a game
+// has finished. It returns the ready player names and whether everyone in
+// the room has now confirmed.
+func (r *Room) SetRematchReady(name string) (ready []string, allReady bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Status != "finished" || r.Match == nil {
+		return nil, false, fmt.Errorf("再戦できるのはゲーム終了後のみです")
+	}
+	if _, ok := r.Players[name]; !ok {
+		return nil, false, fmt.Errorf("ルームに参加していません")
+	}
+	r.Match.SetReady(name)
+	return r.Match.ReadyPlayers(), r.Match.AllReady(r.playerNamesLocked()), nil
+}
+
+// CanStartGame reports why the owner may not start a game now, or nil.
+func (r *Room) CanStartGame() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.nextRoundLocked()
+}
+
+// nextRoundLocked refuses to start the next round of a running match before
+// everyone has confirmed the rematch. Caller must hold r.mu.
+func (r *Room) nextRoundLocked() error {
+	if r.Match != nil && r.Match.Between() && !r.Match.AllReady(r.playerNamesLocked()) {
+		return fmt.Errorf("次のラウンドは全員の準備ができてから始まります")
+	}
+	return nil
+}
+
+// RematchReady reports whether a finished room has every remaining player
+// confirmed for the next round.
+func (r *Room) RematchReady() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Status != "finished" || r.Match == nil {
+		return false
+	}
+	return r.Match.AllReady(r.playerNamesLocked())
+}
+
+// playerNamesLocked returns the current player names. Caller must hold r.mu.
+func (r *Room) playerNamesLocked() []string {
+	names := make([]string, 0, len(r.Players))
+	for name := range r.Players {
+		names = append(names, name)
+	}
+	return names
+}
 
 // ValidateAndSubmitWord delegates to GameEngine for word validation and submission.
 func (r *Room) ValidateAndSubmitWord(word, playerName string) (ValidateResult, string) {
+	return r.SubmitAnswer(word, playerName, -1)
+}
+
+// UseItem spends one of a player's items; see GameEngine.UseItem. The time
+// item is added to the turn timer here.
+func (r *Room) UseItem(playerName, item, kana string) error {
+	r.mu.Lock()
+	if r.Status != "playing" || r.Engine == nil {
+		r.mu.Unlock()
+		return fmt.Errorf("ゲームが開始されていません")
+	}
+	r.mu.Unlock()
+
+	hasVotePending := r.Votes != nil && r.Votes.HasPendingVote()
+	if err := r.Engine.UseItem(playerName, item, kana, hasVotePending); err != nil {
+		return err
+	}
+	if item == ItemTime && r.Timer != nil {
+		r.Timer.Add(itemTimeBonus)
+	}
+	return nil
+}
+
+// SubmitAnswer is ValidateAndSubmitWord for an answer to a given prompt; see
+// GameEngine.SubmitAnswer.
+func (r *Room) SubmitAnswer(word, playerName string, prompt int) (ValidateResult, string) {
 	r.mu.Lock()
 	if r.Status != "playing" || r.Engine == nil {
 		r.mu.Unlock()
@@ -386,7 +652,13 @@ func (r *Room) ValidateAndSubmitWord(word, playerName string) (ValidateResult, s
 	r.mu.Unlock()
 
 	hasVotePending := r.Votes != nil && r.Votes.HasPendingVote()
-	result, msg := r.Engine.ValidateAndSubmitWord(word, playerName, hasVotePending)
+	result, msg := r.Engine.SubmitAnswer(word, playerName, prompt, hasVotePending)
+	if result == ValidateVote && r.Votes != nil {
+		word = normalizeWord(word)
+		if err := r.Votes.StartWordVote(word, toHiragana(word), playerName, msg); err != nil {
+			return ValidateRejected, err.Error()
+		}
+	}
 
 	// Sync player state back to connection-level Player
 	if result == ValidateOK || result == ValidatePenalty {
@@ -468,6 +740,9 @@ func (r *Room) GetLives() map[string]int {
 
 // CastVote delegates to VoteManager. If resolved and challenge rejected, applies revert to game state.
 func (r *Room) CastVote(playerName string, accept bool) (resolved bool, result VoteResolution) {
+	if r.Votes.CanVote(playerName) {
+		r.RecordEvent(GameEvent{Type: EventVote, Actor: playerName, Accepted: &accept})
+	}
 	resolved, result = r.Votes.CastVote(playerName, accept)
 	if resolved {
 		r.applyVoteResult(&result)
@@ -486,6 +761,23 @@ func (r *Room) ForceResolveVote() (resolved bool, result VoteResolution) {
 
 // applyVoteResult applies the game-state side effects of a resolved vote.
 func (r *Room) applyVoteResult(result *VoteResolution) {
+	outcome := "rejected"
+	if result.Accepted {
+		outcome = "accepted"
+	}
+	metrics.votes.Inc(result.Type, outcome)
+	accepted := result.Accepted
+	r.RecordEvent(GameEvent{
+		Type:       EventVoteResult,
+		VoteType:   result.Type,
+		Player:     result.Player,
+		Word:       result.Word,
+		Challenger: result.Challenger,
+		Accepted:   &accepted,
+		Accepts:    result.Accepts,
+		Rejects:    result.Rejects,
+	})
+
 	if result.Type == "genre" {
 		if result.Accepted {
 			pv := r.Votes.GetPending()
@@ -531,12 +823,38 @@ func (r *Room) StartChallengeVote(challengerName string) (VoteInfo, error) {
 		_, ok := r.Players[name]
 		return ok
 	}
-	return r.Votes.StartChallengeVote(challengerName, last, playerExists)
+	info, err := r.Votes.StartChallengeVote(challengerName, last, playerExists)
+	if err != nil {
+		return info, err
+	}
+	r.RecordEvent(GameEvent{
+		Type:       EventChallenge,
+		Actor:      info.Challenger,
+		VoteType:   info.Type,
+		Player:     info.Player,
+		Word:       info.Word,
+		Challenger: info.Challenger,
+		Reason:     info.Reason,
+	})
+	return info, nil
 }
 
 // WithdrawChallenge delegates to VoteManager.
 func (r *Room) WithdrawChallenge(challengerName string) bool {
-	return r.Votes.WithdrawChallenge(challengerName)
+	if !r.Votes.WithdrawChallenge(challengerName) {
+		return false
+	}
+	metrics.votes.Inc("challenge", "withdrawn")
+	r.RecordEvent(GameEvent{Type: EventWithdraw, Actor: challengerName, Challenger: challengerName})
+	return true
+}
+
+// RecordEvent appends an event to the current game's log, if a game has
+// been started in this room.
+func (r *Room) RecordEvent(ev GameEvent) {
+	if r.Engine != nil {
+		r.Engine.RecordEvent(ev)
+	}
 }
 
 // getScoresLocked returns a map of player scores. Caller must hold r.mu.
@@ -570,11 +888,14 @@ func formatAllowedRows(rows []string) string {
 	return strings.Join(rows, "・")
 }
 
-// StopTimer cancels the room's timer.
+// StopTimer cancels the room's timer and puts out its fuse.
 func (r *Room) StopTimer() {
 	if r.Timer != nil {
 		r.Timer.Stop()
 	}
+	if r.Fuse != nil {
+		r.Fuse.Stop()
+	}
 }
 
 // GetState returns a snapshot of the room state for sending to clients.
@@ -607,6 +928,7 @@ func (r *Room) GetState() map[string]any {
 		"type":        "room_state",
 		"roomId":      r.ID,
 		"settings":    r.Settings,
+		"rules":       ruleInfos(r.Settings),
 		"players":     players,
 		"history":     history,
 		"currentWord": currentWord,
@@ -627,5 +949,21 @@ func (r *Room) GetState() map[string]any {
 		maxLives = defaultMaxLives
 	}
 	state["maxLives"] = maxLives
+	if r.Match != nil {
+		state["match"] = r.Match.Summary()
+	}
+	if r.Daily != nil {
+		state["daily"] = r.Daily
+	}
+	if r.Settings.Ladder && r.Engine != nil {
+		state["ladderMin"] = r.Engine.MinLength()
+	}
+	if r.Engine != nil {
+		state["genre"] = r.Engine.ActiveGenre()
+		state["items"] = r.Engine.GetItems()
+		if forced := r.Engine.Forced(); forced != "" {
+			state["forcedKana"] = forced
+		}
+	}
 	return state
 }
@@ -0,0 +1,125 @@
+package srv
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Genre orders for RoomSettings.GenreOrder: how the active genre rotates
+// through RoomSettings.Genres.
+const (
+	GenreSequential = ""       // in list order, wrapping around
+	GenreRandom     = "random" // a different genre at random
+)
+
+const (
+	// maxGenres is how many genres a room may rotate through.
+	maxGenres = 10
+	// maxGenreLen bounds one genre name, in characters.
+	maxGenreLen = 20
+)
+
+// genreList returns the room's rotating genres with blanks dropped.
+func genreList(s RoomSettings) []string {
+	var genres []string
+	for _, g := range s.Genres {
+		if g = strings.TrimSpace(g); g != "" {
+			genres = append(genres, g)
+		}
+	}
+	return genres
+}
+
+// genreLabel names a room's genre for results: the static genre, or the
+// rotating ones joined.
+func genreLabel(s RoomSettings) string {
+	if genres := genreList(s); len(genres) > 0 {
+		return strings.Join(genres, "・")
+	}
+	return s.Genre
+}
+
+// validateGenres checks a room's rotating genres.
+func validateGenres(s RoomSettings) error {
+	genres := genreList(s)
+	if len(genres) > maxGenres {
+		return fmt.Errorf("ジャンルは%d個までです", maxGenres)
+	}
+	for _, g := range genres {
+		if utf8.RuneCountInString(g) > maxGenreLen {
+			return fmt.Errorf("ジャンル「%s」は%d文字以内にしてください", g, maxGenreLen)
+		}
+	}
+	if s.GenreEvery < 0 {
+		return fmt.Errorf("ジャンルを変える間隔が正しくありません")
+	}
+	return nil
+}
+
+// firstGenre returns the genre a game opens with, or "" if the room doesn't
+// rotate genres.
+func firstGenre(s RoomSettings) string {
+	genres := genreList(s)
+	if len(genres) == 0 {
+		return ""
+	}
+	if s.GenreOrder == GenreRandom {
+		return genres[rand.IntN(len(genres))]
+	}
+	return genres[0]
+}
+
+// nextGenre returns the genre that follows current.
+func nextGenre(s RoomSettings, current string) string {
+	genres := genreList(s)
+	if len(genres) < 2 {
+		return current
+	}
+	i := slices.Index(genres, current)
+	if s.GenreOrder == GenreRandom {
+		// Pick among the others so the genre always changes.
+		j := rand.IntN(len(genres) - 1)
+		if i >= 0 && j >= i {
+			j++
+		}
+		return genres[j]
+	}
+	return genres[(i+1)%len(genres)]
+}
+
+// rotateGenreLocked moves to the next genre once every GenreEvery accepted
+// words. Caller must hold ge.mu.
+func (ge *GameEngine) rotateGenreLocked() {
+	if ge.Genre == "" {
+		return
+	}
+	every := max(ge.Settings.GenreEvery, 1)
+	if len(ge.History)%every != 0 {
+		return
+	}
+	ge.setGenreLocked(nextGenre(ge.Settings, ge.Genre))
+}
+
+// setGenreLocked changes the active genre, logging the change. Caller must
+// hold ge.mu.
+func (ge *GameEngine) setGenreLocked(genre string) {
+	if genre == ge.Genre {
+		return
+	}
+	ge.Genre = genre
+	ge.recordLocked(GameEvent{Type: EventGenre, Genre: genre})
+}
+
+// ActiveGenre returns the genre words must fit: the rotating genre if the
+// room has one, else the room's static genre.
+func (ge *GameEngine) ActiveGenre() string {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	if ge.Genre != "" {
+		return ge.Genre
+	}
+	return ge.Settings.Genre
+}
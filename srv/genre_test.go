@@ -0,0 +1,91 @@
+package srv
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenreRotation(t *testing.T) {
+	settings := RoomSettings{Genres: []string{"食べ物", " ", "動物", "国名"}, GenreEvery: 2}
+	ge := NewGameEngine(settings, []string{"alice", "bob"}, nil)
+	if ge.ActiveGenre() != "食べ物" {
+		t.Fatalf("expected the first genre, got %q", ge.ActiveGenre())
+	}
+	words := []string{"りんご", "ごりら", "らっぱ", "ぱせり", "りす", "すいか", "かめ"}
+	want := []string{"食べ物", "食べ物", "動物", "動物", "国名", "国名", "食べ物"}
+	for i, w := range words {
+		if res, _ := ge.ValidateAndSubmitWord(w, ge.CurrentTurn(), false); res != ValidateOK {
+			t.Fatalf("%s: got %v", w, res)
+		}
+		if got := ge.History[i].Genre; got != want[i] {
+			t.Errorf("%s: expected genre %s, got %s", w, want[i], got)
+		}
+	}
+
+	// Taking back the word that completed a rotation undoes it.
+	ge.ValidateAndSubmitWord("めだか", ge.CurrentTurn(), false)
+	if ge.ActiveGenre() != "動物" {
+		t.Fatalf("expected 動物 after めだか, got %q", ge.ActiveGenre())
+	}
+	ge.RevertWord("めだか", "alice")
+	if ge.ActiveGenre() != "食べ物" {
+		t.Errorf("expected the revert to restore 食べ物, got %q", ge.ActiveGenre())
+	}
+	if RestoreGameEngine(settings, ge.ExportState(), nil).ActiveGenre() != "食べ物" {
+		t.Error("expected the genre to survive a snapshot")
+	}
+
+	random := RoomSettings{Genres: []string{"a", "b", "c"}, GenreOrder: GenreRandom}
+	for range 50 {
+		if g := nextGenre(random, "b"); g == "b" || g == "" {
+			t.Fatalf("expected a different genre, got %q", g)
+		}
+	}
+	if g := NewGameEngine(RoomSettings{Genre: "動物"}, nil, nil).ActiveGenre(); g != "動物" {
+		t.Errorf("expected the static genre without rotation, got %q", g)
+	}
+	if err := validateSettings(RoomSettings{Genres: strings.Split("a,b,c,d,e,f,g,h,i,j,k", ",")}); err == nil {
+		t.Error("expected too many genres to be refused")
+	}
+}
+
+func TestGenreChangedBroadcast(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_genre.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("gn01", RoomSettings{Name: "genres", Genres: []string{"食べ物", "動物"}})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	server.handleStartGame(room)
+	server.handleAnswer(room, "alice", "りんご")
+
+	var types []string
+	var changed map[string]any
+	for len(alice.Send) > 0 {
+		var msg map[string]any
+		json.Unmarshal(<-alice.Send, &msg)
+		types = append(types, msg["type"].(string))
+		switch msg["type"] {
+		case "game_started":
+			if msg["genre"] != "食べ物" {
+				t.Errorf("expected game_started to carry the first genre, got %v", msg["genre"])
+			}
+		case "genre_changed":
+			changed = msg
+		}
+	}
+	if changed == nil || changed["genre"] != "動物" || changed["previous"] != "食べ物" {
+		t.Fatalf("expected genre_changed after the first word, got %v (%v)", changed, types)
+	}
+	if room.GetState()["genre"] != "動物" {
+		t.Errorf("expected room_state to carry the active genre")
+	}
+	if ev := room.Engine.EventLog(); ev[len(ev)-1].Type != EventGenre || ev[len(ev)-1].Genre != "動物" {
+		t.Errorf("expected the genre change in the event log, got %+v", ev[len(ev)-1])
+	}
+}
@@ -0,0 +1,161 @@
+package srv
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"unicode/utf8"
+)
+
+// Items a player can use on their turn; see GameEngine.UseItem.
+const (
+	ItemPass    = "pass"    // skip the turn without answering
+	ItemReverse = "reverse" // reverse the turn order
+	ItemForce   = "force"   // pick the kana the next player's word must start with
+	ItemTime    = "time"    // add itemTimeBonus seconds to the turn timer
+)
+
+// powerUps are the items RoomSettings.PowerUps hands out, one of each.
+var powerUps = []string{ItemReverse, ItemForce, ItemTime}
+
+const (
+	// maxPasses is how many pass tokens a room may give each player.
+	maxPasses = 5
+	// itemTimeBonus is how many seconds ItemTime adds to the turn timer.
+	itemTimeBonus = 5
+)
+
+// itemLabel names an item for players.
+func itemLabel(item string) string {
+	switch item {
+	case ItemPass:
+		return "パス"
+	case ItemReverse:
+		return "順番逆転"
+	case ItemForce:
+		return "頭文字指定"
+	case ItemTime:
+		return fmt.Sprintf("+%d秒", itemTimeBonus)
+	}
+	return item
+}
+
+// startingItems returns the items each player starts a game with, or nil if
+// the room has none.
+func startingItems(s RoomSettings) map[string]int {
+	if s.Passes <= 0 && !s.PowerUps {
+		return nil
+	}
+	items := make(map[string]int)
+	if s.Passes > 0 {
+		items[ItemPass] = s.Passes
+	}
+	if s.PowerUps {
+		for _, item := range powerUps {
+			items[item] = 1
+		}
+	}
+	return items
+}
+
+// validateItems checks a room's item settings.
+func validateItems(s RoomSettings) error {
+	if s.Passes < 0 || s.Passes > maxPasses {
+		return fmt.Errorf("パスは%d回までです", maxPasses)
+	}
+	if s.Race && (s.Passes > 0 || s.PowerUps) {
+		return fmt.Errorf("早い者勝ちモードではアイテムは使えません")
+	}
+	return nil
+}
+
+// forcedKana checks the kana a player picked for ItemForce against the rules
+// in s and returns it in hiragana.
+func forcedKana(kana string, s RoomSettings) (string, error) {
+	kana = toHiragana(normalizeWord(kana))
+	r, size := utf8.DecodeRuneInString(kana)
+	if size == 0 || size != len(kana) || !isHiragana(r) || normalizeSmallKana(r) != r || r == 'ん' {
+		return "", fmt.Errorf("頭文字にするひらがなを1文字選んでください")
+	}
+	if !playableFrom(r, s) {
+		return "", fmt.Errorf("「%s」から始まる言葉はこのルームのルールでは使えません", kana)
+	}
+	return kana, nil
+}
+
+// UseItem spends one of the player's items on their turn. kana is the
+// starting kana picked for ItemForce. ItemTime is only validated and spent
+// here; the caller adds the time to the room's timer. Acquires lock.
+func (ge *GameEngine) UseItem(playerName, item, kana string, hasVotePending bool) error {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+
+	if hasVotePending {
+		return fmt.Errorf("投票中はアイテムを使えません")
+	}
+	ps, ok := ge.Players[playerName]
+	if !ok || ps.Lives <= 0 {
+		return fmt.Errorf("アイテムを使えません")
+	}
+	if ge.Settings.Race || len(ge.TurnOrder) == 0 || ge.TurnOrder[ge.TurnIndex] != playerName {
+		return fmt.Errorf("アイテムは自分の番に使ってください")
+	}
+	if ps.Items[item] <= 0 {
+		return fmt.Errorf("「%s」を持っていません", itemLabel(item))
+	}
+
+	ev := GameEvent{Type: EventItem, Actor: playerName, Player: playerName, Item: item}
+	switch item {
+	case ItemPass:
+		// The next player continues from the same word, under the same rules.
+		// A force this player queued lands on the next player, as it would
+		// have after a word.
+		if ge.QueuedKana != "" {
+			ge.ForcedKana, ge.QueuedKana = ge.QueuedKana, ""
+		}
+		ge.advanceTurnLocked()
+		if ge.resetTimer != nil {
+			ge.resetTimer()
+		}
+	case ItemReverse:
+		slices.Reverse(ge.TurnOrder)
+		ge.TurnIndex = len(ge.TurnOrder) - 1 - ge.TurnIndex
+		ev.TurnOrder = slices.Clone(ge.TurnOrder)
+	case ItemForce:
+		k, err := forcedKana(kana, ge.Settings)
+		if err != nil {
+			return err
+		}
+		// Takes effect once this player's word is played.
+		ge.QueuedKana = k
+		ev.Text = k
+	case ItemTime:
+		if ge.Settings.TimeLimit <= 0 {
+			return fmt.Errorf("制限時間のないルームでは使えません")
+		}
+	default:
+		return fmt.Errorf("「%s」を持っていません", itemLabel(item))
+	}
+	ps.Items[item]--
+	ge.recordLocked(ev)
+	return nil
+}
+
+// Forced returns the kana a force item requires the next word to start
+// with, or "".
+func (ge *GameEngine) Forced() string {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	return ge.ForcedKana
+}
+
+// GetItems returns a map of player name -> items left.
+func (ge *GameEngine) GetItems() map[string]map[string]int {
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+	items := make(map[string]map[string]int, len(ge.Players))
+	for name, ps := range ge.Players {
+		items[name] = maps.Clone(ps.Items)
+	}
+	return items
+}
@@ -0,0 +1,223 @@
+package srv
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestUseItem(t *testing.T) {
+	settings := RoomSettings{Passes: 1, PowerUps: true, TimeLimit: 30}
+	ge := NewGameEngine(settings, []string{"alice", "bob", "carol"}, nil)
+
+	if err := ge.UseItem("bob", ItemPass, "", false); err == nil {
+		t.Error("expected items to be refused out of turn")
+	}
+	if err := ge.UseItem("alice", ItemPass, "", true); err == nil {
+		t.Error("expected items to be refused during a vote")
+	}
+	if err := ge.UseItem("alice", ItemPass, "", false); err != nil {
+		t.Fatalf("pass: %v", err)
+	}
+	if ge.CurrentTurn() != "bob" || ge.Players["alice"].Items[ItemPass] != 0 {
+		t.Fatalf("expected the pass to be spent and the turn passed, got %q %v", ge.CurrentTurn(), ge.Players["alice"].Items)
+	}
+
+	if err := ge.UseItem("bob", ItemReverse, "", false); err != nil {
+		t.Fatalf("reverse: %v", err)
+	}
+	if !slices.Equal(ge.TurnOrder, []string{"carol", "bob", "alice"}) || ge.CurrentTurn() != "bob" {
+		t.Fatalf("expected the order reversed with bob still up, got %v %q", ge.TurnOrder, ge.CurrentTurn())
+	}
+	for _, kana := range []string{"ん", "かさ", "ゃ", "a"} {
+		if err := ge.UseItem("bob", ItemForce, kana, false); err == nil {
+			t.Errorf("expected %q to be refused as a starting kana", kana)
+		}
+	}
+	if err := ge.UseItem("bob", ItemForce, "カ", false); err != nil {
+		t.Fatalf("force: %v", err)
+	}
+	if err := ge.UseItem("bob", ItemForce, "さ", false); err == nil {
+		t.Error("expected a spent item to be refused")
+	}
+	if res, _ := ge.ValidateAndSubmitWord("ねこ", "bob", false); res != ValidateOK {
+		t.Fatalf("expected bob's word to be accepted, got %v", res)
+	}
+	if ge.CurrentTurn() != "alice" || ge.Forced() != "か" {
+		t.Fatalf("expected alice to be forced to か, got %q %q", ge.CurrentTurn(), ge.Forced())
+	}
+	if res, msg := ge.ValidateAndSubmitWord("こま", "alice", false); res != ValidateRejected || msg != "「か」から始まる言葉を入力してください（頭文字指定）" {
+		t.Errorf("expected the chain to be overridden, got %v %q", res, msg)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("かめ", "alice", false); res != ValidateOK {
+		t.Fatalf("expected かめ to be accepted, got %v", res)
+	}
+	if ge.History[1].Forced != "か" || ge.Forced() != "" {
+		t.Errorf("expected the force to be recorded and used up, got %+v %q", ge.History[1], ge.Forced())
+	}
+
+	if err := ge.UseItem("carol", ItemTime, "", false); err != nil {
+		t.Errorf("time: %v", err)
+	}
+	if err := NewGameEngine(RoomSettings{PowerUps: true}, []string{"alice"}, nil).UseItem("alice", ItemTime, "", false); err == nil {
+		t.Error("expected the time item to be refused without a time limit")
+	}
+
+	var used []string
+	for _, ev := range ge.EventLog() {
+		if ev.Type == EventItem {
+			used = append(used, ev.Item)
+		}
+	}
+	if !slices.Equal(used, []string{ItemPass, ItemReverse, ItemForce, ItemTime}) {
+		t.Errorf("expected every item use in the event log, got %v", used)
+	}
+
+	restored := RestoreGameEngine(settings, ge.ExportState(), nil)
+	ge.Players["bob"].Items[ItemPass] = 0
+	if restored.Players["bob"].Items[ItemPass] != 1 || restored.Players["carol"].Items[ItemTime] != 0 {
+		t.Errorf("expected items to survive a snapshot, got %v", restored.GetItems())
+	}
+
+	if err := validateSettings(RoomSettings{Passes: maxPasses + 1}); err == nil {
+		t.Error("expected too many passes to be refused")
+	}
+	if err := validateSettings(RoomSettings{PowerUps: true, Race: true}); err == nil {
+		t.Error("expected items to be refused in a race")
+	}
+}
+
+func TestForceThenPass(t *testing.T) {
+	ge := NewGameEngine(RoomSettings{Passes: 1, PowerUps: true}, []string{"alice", "bob", "carol"}, nil)
+	ge.ValidateAndSubmitWord("ねこ", "alice", false)
+	if err := ge.UseItem("bob", ItemForce, "か", false); err != nil {
+		t.Fatalf("force: %v", err)
+	}
+	if err := ge.UseItem("bob", ItemPass, "", false); err != nil {
+		t.Fatalf("pass: %v", err)
+	}
+	if ge.CurrentTurn() != "carol" || ge.Forced() != "か" || ge.QueuedKana != "" {
+		t.Fatalf("expected the force to land on carol, got %q %q %q", ge.CurrentTurn(), ge.Forced(), ge.QueuedKana)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("こま", "carol", false); res != ValidateRejected {
+		t.Errorf("expected carol to be held to か, got %v", res)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("かめ", "carol", false); res != ValidateOK {
+		t.Fatalf("expected かめ to be accepted, got %v", res)
+	}
+	if ge.Forced() != "" {
+		t.Errorf("expected the force to be used up, got %q", ge.Forced())
+	}
+}
+
+func TestForcedKanaFollowsRoomRules(t *testing.T) {
+	for _, tc := range []struct {
+		settings RoomSettings
+		kana     string
+	}{
+		{RoomSettings{NoDakuten: true}, "が"},
+		{RoomSettings{NoDakuten: true}, "ぱ"},
+		{RoomSettings{AllowedRows: []string{"あ行", "か行"}}, "さ"},
+	} {
+		if _, err := forcedKana(tc.kana, tc.settings); err == nil {
+			t.Errorf("expected %s to be refused under %+v", tc.kana, tc.settings)
+		}
+	}
+	if k, err := forcedKana("カ", RoomSettings{NoDakuten: true, AllowedRows: []string{"か行"}}); err != nil || k != "か" {
+		t.Errorf("expected か to be allowed, got %q %v", k, err)
+	}
+}
+
+func TestUseItemMessages(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_items.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("it01", RoomSettings{Name: "items", Passes: 1, PowerUps: true, TimeLimit: 30})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	bob := &Player{Name: "bob", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	room.AddPlayer(bob)
+	server.handleStartGame(room)
+	defer room.StopTimer()
+
+	server.handleUseItem(room, "alice", ItemPass, "")
+	server.handleUseItem(room, "alice", ItemPass, "")
+	server.handleUseItem(room, "bob", ItemTime, "")
+
+	var used []map[string]any
+	var rejected map[string]any
+	for len(alice.Send) > 0 {
+		var msg map[string]any
+		json.Unmarshal(<-alice.Send, &msg)
+		switch msg["type"] {
+		case "game_started":
+			if items, _ := msg["items"].(map[string]any); items["bob"] == nil {
+				t.Errorf("expected game_started to carry the items, got %v", msg["items"])
+			}
+		case "item_used":
+			used = append(used, msg)
+		case "item_rejected":
+			rejected = msg
+		}
+	}
+	if len(used) != 2 || used[0]["currentTurn"] != "bob" || used[0]["label"] != "パス" {
+		t.Fatalf("expected the pass and the time item to be announced, got %v", used)
+	}
+	if left := used[1]["timeLeft"].(float64); left < 33 {
+		t.Errorf("expected +%d seconds on the timer, got %v", itemTimeBonus, left)
+	}
+	if rejected == nil || rejected["message"] != "アイテムは自分の番に使ってください" {
+		t.Errorf("expected alice's second pass to be refused, got %v", rejected)
+	}
+	for len(bob.Send) > 0 {
+		var msg map[string]any
+		json.Unmarshal(<-bob.Send, &msg)
+		if msg["type"] == "item_rejected" {
+			t.Error("expected the refusal to go to alice only")
+		}
+	}
+}
+
+func TestPassReportsForcedKana(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_items.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("it02", RoomSettings{Name: "items", Passes: 1, PowerUps: true})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	room.AddPlayer(&Player{Name: "bob", Send: make(chan []byte, 256)})
+	room.AddPlayer(&Player{Name: "carol", Send: make(chan []byte, 256)})
+	server.handleStartGame(room)
+	defer room.StopTimer()
+
+	// The turn order is shuffled at the start.
+	_, _, order, _ := room.Engine.Snapshot()
+	room.Engine.ValidateAndSubmitWord("ねこ", order[0], false)
+	server.handleUseItem(room, order[1], ItemForce, "か")
+	server.handleUseItem(room, order[1], ItemPass, "")
+
+	var used []map[string]any
+	for len(alice.Send) > 0 {
+		var msg map[string]any
+		json.Unmarshal(<-alice.Send, &msg)
+		if msg["type"] == "item_used" {
+			used = append(used, msg)
+		}
+	}
+	if len(used) != 2 {
+		t.Fatalf("expected the force and the pass to be announced, got %v", used)
+	}
+	if _, ok := used[0]["forcedKana"]; ok {
+		t.Errorf("expected the force to wait for the turn to end, got %v", used[0])
+	}
+	if used[1]["currentTurn"] != order[2] || used[1]["forcedKana"] != "か" {
+		t.Errorf("expected the pass to hand the force to %s, got %v", order[2], used[1])
+	}
+}
@@ -11,20 +11,22 @@ var smallToNormalKana = map[rune]rune{
 	'ぁ': 'あ', 'ぃ': 'い', 'ぅ': 'う', 'ぇ': 'え', 'ぉ': 'お',
 	'っ': 'つ',
 	'ゎ': 'わ',
+	'ゕ': 'か', 'ゖ': 'け',
 }
 
 // katakanaToHiragana converts a single katakana rune to hiragana.
 // If the rune is not katakana, it is returned unchanged.
 func katakanaToHiragana(r rune) rune {
-	// Katakana range: 0x30A1 (ァ) – 0x30FA (ヺ) → Hiragana 0x3041–0x309A
-	if r >= 0x30A1 && r <= 0x30FA {
+	// Katakana range: 0x30A1 (ァ) – 0x30F6 (ヶ) → Hiragana 0x3041–0x3096.
+	// ヷ–ヺ have no hiragana; normalizeWord spells them with ヴ.
+	if r >= 0x30A1 && r <= 0x30F6 {
 		return r - 0x60
 	}
 	return r
 }
 
-// toHiragana converts an entire string from katakana to hiragana.
-// Non-katakana characters are left unchanged.
+// toHiragana converts the katakana in a normalized word (see normalizeWord)
+// to hiragana. Non-katakana characters are left unchanged.
 func toHiragana(s string) string {
 	var b strings.Builder
 	b.Grow(len(s))
@@ -34,14 +36,16 @@ func toHiragana(s string) string {
 	return b.String()
 }
 
-// isHiragana checks if a rune is hiragana (including ん).
+// isHiragana checks if a rune is a hiragana letter (ぁ–ゖ, including ん).
+// Voiced sound and iteration marks are not letters.
 func isHiragana(r rune) bool {
-	return r >= 0x3040 && r <= 0x309F
+	return r >= 0x3041 && r <= 0x3096
 }
 
-// isKatakana checks if a rune is katakana.
+// isKatakana checks if a rune is a katakana letter (ァ–ヺ). ー, ・ and the
+// iteration marks are not letters.
 func isKatakana(r rune) bool {
-	return r >= 0x30A0 && r <= 0x30FF
+	return r >= 0x30A1 && r <= 0x30FA
 }
 
 // isLongVowelMark checks if a rune is ー.
@@ -49,13 +53,17 @@ func isLongVowelMark(r rune) bool {
 	return r == 'ー'
 }
 
-// isJapanese returns true if the string contains only hiragana, katakana, or long vowel marks.
-// For our shiritori game, we require words to be in kana only (no kanji).
+// isJapanese returns true if the string contains only hiragana, katakana, or long vowel marks,
+// starting with a kana. For our shiritori game, we require words to be in kana only (no kanji).
+// Input should already be normalized (see normalizeWord).
 func isJapanese(s string) bool {
 	if utf8.RuneCountInString(s) == 0 {
 		return false
 	}
-	for _, r := range s {
+	for i, r := range s {
+		if i == 0 && isLongVowelMark(r) {
+			return false
+		}
 		if !isHiragana(r) && !isKatakana(r) && !isLongVowelMark(r) {
 			return false
 		}
@@ -114,6 +122,93 @@ func charCount(s string) int {
 	return utf8.RuneCountInString(s)
 }
 
+// Length units for RoomSettings.LengthUnit: how MinLen and MaxLen measure a word.
+const (
+	LengthChars    = ""         // characters as written: きゃく is 3
+	LengthMora     = "mora"     // morae: きゃく is 2, らーめん is 4
+	LengthSyllable = "syllable" // like mora, but っ and ー join the kana before: らーめん is 3
+)
+
+// MoraOptions controls how moraCount treats the sounds that only lengthen
+// the kana before them.
+type MoraOptions struct {
+	Sokuon bool // count っ as its own mora
+	Choon  bool // count ー as its own mora
+}
+
+// yoonKana are the small kana that merge with the kana before them into one
+// mora (きゃ, ふぁ, くゎ).
+var yoonKana = map[rune]bool{
+	'ゃ': true, 'ゅ': true, 'ょ': true,
+	'ぁ': true, 'ぃ': true, 'ぅ': true, 'ぇ': true, 'ぉ': true,
+	'ゎ': true,
+}
+
+// moraCount returns the number of morae in a hiragana word. Small ゃゅょぁぃぅぇぉゎ
+// merge with the kana before them; っ and ー count only as opts say; ん always counts.
+func moraCount(hiragana string, opts MoraOptions) int {
+	n := 0
+	prevKana := false
+	for _, r := range hiragana {
+		switch {
+		case yoonKana[r] && prevKana:
+			// part of the previous mora
+		case r == 'っ':
+			if opts.Sokuon {
+				n++
+			}
+		case isLongVowelMark(r):
+			if opts.Choon {
+				n++
+			}
+		default:
+			n++
+		}
+		prevKana = isHiragana(r) && r != 'っ'
+	}
+	return n
+}
+
+// linkMora splits a hiragana word into the morae that can link words in
+// multi-mora shiritori: each kana with any small kana merged into it (きゃ,
+// ふぁ). っ and ー are left out, since no word starts with them.
+func linkMora(hiragana string) []string {
+	var morae []string
+	for _, r := range hiragana {
+		switch {
+		case r == 'っ' || isLongVowelMark(r):
+			continue
+		case yoonKana[r] && len(morae) > 0:
+			morae[len(morae)-1] += string(r)
+		default:
+			morae = append(morae, string(r))
+		}
+	}
+	return morae
+}
+
+// wordLength measures a hiragana word in the given length unit.
+func wordLength(hiragana, unit string) int {
+	switch unit {
+	case LengthMora:
+		return moraCount(hiragana, MoraOptions{Sokuon: true, Choon: true})
+	case LengthSyllable:
+		return moraCount(hiragana, MoraOptions{})
+	}
+	return charCount(hiragana)
+}
+
+// lengthUnitName returns the word used for a length unit in messages.
+func lengthUnitName(unit string) string {
+	switch unit {
+	case LengthMora:
+		return "音"
+	case LengthSyllable:
+		return "音節"
+	}
+	return "文字"
+}
+
 // KanaRow represents a row (行) of the Japanese kana table.
 type KanaRow struct {
 	Name  string // e.g. "あ行"
@@ -124,7 +219,7 @@ type KanaRow struct {
 // KanaRows defines all kana rows in standard order.
 // Dakuten/handakuten variants are grouped with their base row.
 var KanaRows = []KanaRow{
-	{Name: "あ行", Label: "あ", Chars: []rune{'あ', 'い', 'う', 'え', 'お'}},
+	{Name: "あ行", Label: "あ", Chars: []rune{'あ', 'い', 'う', 'え', 'お', 'ゔ'}},
 	{Name: "か行", Label: "か", Chars: []rune{'か', 'き', 'く', 'け', 'こ', 'が', 'ぎ', 'ぐ', 'げ', 'ご'}},
 	{Name: "さ行", Label: "さ", Chars: []rune{'さ', 'し', 'す', 'せ', 'そ', 'ざ', 'じ', 'ず', 'ぜ', 'ぞ'}},
 	{Name: "た行", Label: "た", Chars: []rune{'た', 'ち', 'つ', 'て', 'と', 'だ', 'ぢ', 'づ', 'で', 'ど'}},
@@ -133,7 +228,7 @@ var KanaRows = []KanaRow{
 	{Name: "ま行", Label: "ま", Chars: []rune{'ま', 'み', 'む', 'め', 'も'}},
 	{Name: "や行", Label: "や", Chars: []rune{'や', 'ゆ', 'よ'}},
 	{Name: "ら行", Label: "ら", Chars: []rune{'ら', 'り', 'る', 'れ', 'ろ'}},
-	{Name: "わ行", Label: "わ", Chars: []rune{'わ', 'を', 'ん'}},
+	{Name: "わ行", Label: "わ", Chars: []rune{'わ', 'ゐ', 'ゑ', 'を', 'ん'}},
 }
 
 // kanaRowMap maps each hiragana character to its row name.
@@ -197,6 +292,7 @@ var dakutenSet = map[rune]bool{
 	'ざ': true, 'じ': true, 'ず': true, 'ぜ': true, 'ぞ': true,
 	'だ': true, 'ぢ': true, 'づ': true, 'で': true, 'ど': true,
 	'ば': true, 'び': true, 'ぶ': true, 'べ': true, 'ぼ': true,
+	'ゔ': true,
 }
 
 // handakutenSet contains all hiragana characters with handakuten (半濁点).
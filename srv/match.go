@@ -0,0 +1,389 @@
+package srv
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RoundResult records the outcome of a single round within a match.
+type RoundResult struct {
+	Round    int            `json:"round"`
+	Winner   string         `json:"winner"`
+	Loser    string         `json:"loser"`
+	ResultID string         `json:"resultId,omitempty"`
+	Scores   map[string]int `json:"scores"`
+}
+
+// MatchState tracks cumulative results across the rounds of a best-of-N match
+// and the rematch ready-check between rounds.
+type MatchState struct {
+	mu     sync.Mutex
+	ID     string
+	BestOf int
+	Rounds []RoundResult
+	Wins   map[string]int
+	Winner string // set once a player has won the match
+	Ready  map[string]bool
+}
+
+// MatchSummary is a snapshot of a match for sending to clients.
+type MatchSummary struct {
+	ID         string         `json:"id"`
+	BestOf     int            `json:"bestOf"`
+	Round      int            `json:"round"`
+	Wins       map[string]int `json:"wins"`
+	Rounds     []RoundResult  `json:"rounds"`
+	Winner     string         `json:"winner,omitempty"`
+	Over       bool           `json:"over"`
+	ReadyCount int            `json:"readyCount"`
+}
+
+// NewMatchState creates a match of bestOf rounds. Values below 1 mean a single round.
+func NewMatchState(bestOf int) *MatchState {
+	if bestOf < 1 {
+		bestOf = 1
+	}
+	return &MatchState{
+		ID:     generateResultID(),
+		BestOf: bestOf,
+		Rounds: []RoundResult{},
+		Wins:   make(map[string]int),
+		Ready:  make(map[string]bool),
+	}
+}
+
+// RestoreMatchState rebuilds a MatchState from a persisted summary.
+// The rematch ready-check is not restored.
+func RestoreMatchState(sum MatchSummary) *MatchState {
+	m := NewMatchState(sum.BestOf)
+	if sum.ID != "" {
+		m.ID = sum.ID
+	}
+	if sum.Rounds != nil {
+		m.Rounds = sum.Rounds
+	}
+	if sum.Wins != nil {
+		m.Wins = sum.Wins
+	}
+	m.Winner = sum.Winner
+	return m
+}
+
+// winsNeeded returns the number of round wins that decides the match.
+func (m *MatchState) winsNeeded() int {
+	return m.BestOf/2 + 1
+}
+
+// NextRound returns the 1-based number of the round that would be played next.
+func (m *MatchState) NextRound() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.Rounds) + 1
+}
+
+// Over reports whether the match has been decided or all rounds are played.
+func (m *MatchState) Over() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.overLocked()
+}
+
+func (m *MatchState) overLocked() bool {
+	return m.Winner != "" || len(m.Rounds) >= m.BestOf
+}
+
+// Between reports whether the match has played a round and has more to play.
+func (m *MatchState) Between() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.Rounds) > 0 && !m.overLocked()
+}
+
+// RecordRound appends a finished round, updates the win tally and
+// decides the match winner once someone reaches the required wins.
+// Returns the recorded round.
+func (m *MatchState) RecordRound(winner, loser, resultID string, scores map[string]int) RoundResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rr := RoundResult{
+		Round:    len(m.Rounds) + 1,
+		Winner:   winner,
+		Loser:    loser,
+		ResultID: resultID,
+		Scores:   scores,
+	}
+	m.Rounds = append(m.Rounds, rr)
+	if winner != "" {
+		m.Wins[winner]++
+		if m.Wins[winner] >= m.winsNeeded() {
+			m.Winner = winner
+		}
+	}
+	if m.Winner == "" && len(m.Rounds) >= m.BestOf {
+		m.Winner = m.leaderLocked()
+	}
+	m.Ready = make(map[string]bool)
+	return rr
+}
+
+// leaderLocked returns the player with the most round wins, or "" on a tie.
+// Caller must hold m.mu.
+func (m *MatchState) leaderLocked() string {
+	leader, best, tied := "", 0, false
+	for name, w := range m.Wins {
+		switch {
+		case w > best:
+			leader, best, tied = name, w, false
+		case w == best:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return leader
+}
+
+// LastLoser returns the loser of the most recent round, or "" if none.
+func (m *MatchState) LastLoser() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.Rounds) == 0 {
+		return ""
+	}
+	return m.Rounds[len(m.Rounds)-1].Loser
+}
+
+// SetReady marks a player as ready for the next round.
+func (m *MatchState) SetReady(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Ready[name] = true
+}
+
+// ClearReady removes a player's ready flag (e.g. when they leave).
+func (m *MatchState) ClearReady(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Ready, name)
+}
+
+// AllReady reports whether every given player has confirmed the rematch.
+func (m *MatchState) AllReady(players []string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(players) == 0 {
+		return false
+	}
+	for _, name := range players {
+		if !m.Ready[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadyPlayers returns the sorted names of players who confirmed the rematch.
+func (m *MatchState) ReadyPlayers() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.Ready))
+	for name := range m.Ready {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Summary returns a snapshot of the match for clients.
+func (m *MatchState) Summary() MatchSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wins := make(map[string]int, len(m.Wins))
+	for k, v := range m.Wins {
+		wins[k] = v
+	}
+	rounds := make([]RoundResult, len(m.Rounds))
+	copy(rounds, m.Rounds)
+	return MatchSummary{
+		ID:         m.ID,
+		BestOf:     m.BestOf,
+		Round:      len(m.Rounds),
+		Wins:       wins,
+		Rounds:     rounds,
+		Winner:     m.Winner,
+		Over:       m.overLocked(),
+		ReadyCount: len(m.Ready),
+	}
+}
+
+// roundWinner picks the winner of a round from a game_over message.
+// When the game ended without a last survivor (e.g. timeout), the top scorer
+// other than the loser wins; a tie means no winner.
+func roundWinner(winner, loser string, scores map[string]int) string {
+	if winner != "" {
+		return winner
+	}
+	best, top, tied := -1, "", false
+	for name, sc := range scores {
+		if name == loser {
+			continue
+		}
+		switch {
+		case sc > best:
+			best, top, tied = sc, name, false
+		case sc == best:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return top
+}
+
+// recordMatchRound records a finished round on the room's match, persists the
+// aggregate match result for multi-round matches and returns the summary.
+func (s *Server) recordMatchRound(room *Room, roomName, winner, loser, resultID string, scores map[string]int) *MatchSummary {
+	m := room.Match
+	if m == nil {
+		return nil
+	}
+	m.RecordRound(roundWinner(winner, loser, scores), loser, resultID, scores)
+	summary := m.Summary()
+	if summary.BestOf > 1 {
+		if err := s.saveMatchResult(roomName, summary); err != nil {
+			slog.Error("save match result", "error", err)
+		}
+	}
+	return &summary
+}
+
+// saveMatchResult inserts or updates the aggregate result of a match.
+func (s *Server) saveMatchResult(roomName string, m MatchSummary) error {
+	defer metrics.dbWriteLatency.ObserveSince(time.Now(), "save_match_result")
+	winsJSON, _ := json.Marshal(m.Wins)
+	roundsJSON, _ := json.Marshal(m.Rounds)
+	now := time.Now().UTC()
+	_, err := s.DB.Exec(
+		`INSERT INTO match_results (id, room_name, best_of, winner, wins_json, rounds_json, finished, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+		   winner = excluded.winner,
+		   wins_json = excluded.wins_json,
+		   rounds_json = excluded.rounds_json,
+		   finished = excluded.finished,
+		   updated_at = excluded.updated_at`,
+		m.ID, roomName, m.BestOf, m.Winner, string(winsJSON), string(roundsJSON), m.Over, now, now,
+	)
+	return err
+}
+
+// MatchResult is the stored aggregate result of a multi-round match.
+type MatchResult struct {
+	ID        string         `json:"id"`
+	RoomName  string         `json:"roomName"`
+	BestOf    int            `json:"bestOf"`
+	Winner    string         `json:"winner"`
+	Wins      map[string]int `json:"wins"`
+	Rounds    []RoundResult  `json:"rounds"`
+	Finished  bool           `json:"finished"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+}
+
+// loadMatchResult loads an aggregate match result from the database.
+func (s *Server) loadMatchResult(id string) (*MatchResult, error) {
+	var (
+		result    MatchResult
+		winsStr   string
+		roundsStr string
+	)
+	err := s.DB.QueryRow(
+		`SELECT id, room_name, best_of, winner, wins_json, rounds_json, finished, created_at, updated_at
+		 FROM match_results WHERE id = ?`, id,
+	).Scan(&result.ID, &result.RoomName, &result.BestOf, &result.Winner, &winsStr, &roundsStr,
+		&result.Finished, &result.CreatedAt, &result.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal([]byte(winsStr), &result.Wins)
+	json.Unmarshal([]byte(roundsStr), &result.Rounds)
+	return &result, nil
+}
+
+// HandleMatchResult returns the aggregate result of a match as JSON.
+func (s *Server) HandleMatchResult(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		http.NotFound(w, r)
+		return
+	}
+	result, err := s.loadMatchResult(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("load match result", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if err := s.hidePrivateRounds(r, result); err != nil {
+		slog.Error("load match result", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// hidePrivateRounds leaves out the rounds whose result is private, unless the
+// request carries that result's owner token (?token=, repeatable).
+func (s *Server) hidePrivateRounds(r *http.Request, result *MatchResult) error {
+	rows, err := s.DB.Query(`SELECT id FROM game_results WHERE match_id = ? AND visibility = ?`,
+		result.ID, VisibilityPrivate)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	private := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		private[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(private) == 0 {
+		return nil
+	}
+	tokens := r.URL.Query()["token"]
+	visible := result.Rounds[:0]
+	for _, round := range result.Rounds {
+		if private[round.ResultID] && !s.anyResultToken(round.ResultID, tokens) {
+			continue
+		}
+		visible = append(visible, round)
+	}
+	result.Rounds = visible
+	return nil
+}
+
+func (s *Server) anyResultToken(id string, tokens []string) bool {
+	for _, t := range tokens {
+		if s.validResultToken(id, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchBestOfThree(t *testing.T) {
+	m := NewMatchState(3)
+
+	m.RecordRound("alice", "bob", "r1", map[string]int{"alice": 3, "bob": 1})
+	if m.Over() {
+		t.Fatal("match should not be over after one round")
+	}
+
+	m.RecordRound("bob", "alice", "r2", map[string]int{"alice": 2, "bob": 4})
+	if m.Over() {
+		t.Fatal("match should not be over at 1-1")
+	}
+	if got := m.LastLoser(); got != "alice" {
+		t.Errorf("expected last loser alice, got %q", got)
+	}
+
+	m.RecordRound("alice", "bob", "r3", map[string]int{"alice": 5, "bob": 2})
+	if !m.Over() {
+		t.Fatal("match should be over after alice's second win")
+	}
+	summary := m.Summary()
+	if summary.Winner != "alice" {
+		t.Errorf("expected match winner alice, got %q", summary.Winner)
+	}
+	if summary.Wins["alice"] != 2 || summary.Wins["bob"] != 1 {
+		t.Errorf("unexpected wins: %v", summary.Wins)
+	}
+	if len(summary.Rounds) != 3 || summary.Rounds[2].ResultID != "r3" {
+		t.Errorf("unexpected rounds: %+v", summary.Rounds)
+	}
+}
+
+func TestMatchDecidedEarly(t *testing.T) {
+	m := NewMatchState(5)
+	m.RecordRound("alice", "bob", "", nil)
+	m.RecordRound("alice", "bob", "", nil)
+	if m.Over() {
+		t.Fatal("best of 5 should not be decided after two wins")
+	}
+	m.RecordRound("alice", "bob", "", nil)
+	if !m.Over() {
+		t.Fatal("best of 5 should be decided after three wins")
+	}
+}
+
+func TestMatchSingleRoundDefault(t *testing.T) {
+	m := NewMatchState(0)
+	if m.BestOf != 1 {
+		t.Fatalf("expected BestOf=1, got %d", m.BestOf)
+	}
+	m.RecordRound("", "bob", "", map[string]int{"alice": 1, "bob": 1})
+	if !m.Over() {
+		t.Error("single round match should be over after one round")
+	}
+}
+
+func TestRoundWinnerFromScores(t *testing.T) {
+	scores := map[string]int{"alice": 4, "bob": 6, "carol": 2}
+	if got := roundWinner("", "bob", scores); got != "alice" {
+		t.Errorf("expected alice (top scorer excluding loser), got %q", got)
+	}
+	if got := roundWinner("carol", "bob", scores); got != "carol" {
+		t.Errorf("expected explicit winner carol, got %q", got)
+	}
+	tied := map[string]int{"alice": 3, "bob": 1, "carol": 3}
+	if got := roundWinner("", "bob", tied); got != "" {
+		t.Errorf("expected no winner on tie, got %q", got)
+	}
+}
+
+func TestRematchReadyCheck(t *testing.T) {
+	room := &Room{
+		Owner: "alice",
+		Players: map[string]*Player{
+			"alice": {Name: "alice", Send: make(chan []byte, 256)},
+			"bob":   {Name: "bob", Send: make(chan []byte, 256)},
+		},
+		Status: "waiting",
+	}
+
+	if _, _, err := room.SetRematchReady("alice"); err == nil {
+		t.Fatal("rematch should not be allowed before a game has finished")
+	}
+
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	room.Status = "finished"
+	room.Match.RecordRound("alice", "bob", "", nil)
+
+	_, allReady, err := room.SetRematchReady("alice")
+	if err != nil {
+		t.Fatalf("set ready: %v", err)
+	}
+	if allReady {
+		t.Fatal("should not be all ready with one of two players")
+	}
+	ready, allReady, _ := room.SetRematchReady("bob")
+	if !allReady || len(ready) != 2 {
+		t.Fatalf("expected both players ready, got %v (all=%v)", ready, allReady)
+	}
+}
+
+func TestNextRoundWaitsForReady(t *testing.T) {
+	room := &Room{
+		Owner:    "alice",
+		Settings: RoomSettings{Rounds: 3},
+		Players: map[string]*Player{
+			"alice": {Name: "alice", Send: make(chan []byte, 256)},
+			"bob":   {Name: "bob", Send: make(chan []byte, 256)},
+		},
+		Status: "waiting",
+	}
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	room.Status = "finished"
+	room.Match.RecordRound("alice", "bob", "", nil)
+
+	if err := room.UpdateSettings(RoomSettings{Rounds: 1}); err == nil {
+		t.Error("expected the number of rounds to be fixed mid-match")
+	}
+	if err := room.UpdateSettings(RoomSettings{Rounds: 3}); err != nil {
+		t.Errorf("expected other settings to change mid-match: %v", err)
+	}
+
+	room.SetRematchReady("alice")
+	if err := room.CanStartGame(); err == nil {
+		t.Error("expected the owner to wait for everyone before the next round")
+	}
+	if err := room.StartGame(); err == nil {
+		t.Fatal("expected the next round to wait for everyone")
+	}
+	room.SetRematchReady("bob")
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start second round: %v", err)
+	}
+}
+
+func TestLoserStartsNextRound(t *testing.T) {
+	room := &Room{
+		Owner:    "alice",
+		Settings: RoomSettings{Rounds: 3},
+		Players: map[string]*Player{
+			"alice": {Name: "alice", Send: make(chan []byte, 256)},
+			"bob":   {Name: "bob", Send: make(chan []byte, 256)},
+			"carol": {Name: "carol", Send: make(chan []byte, 256)},
+		},
+		Status: "waiting",
+	}
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	if got := room.Engine.CurrentTurn(); got != "alice" {
+		t.Fatalf("expected owner alice to start the first round, got %q", got)
+	}
+	match := room.Match
+
+	room.Status = "finished"
+	match.RecordRound("alice", "carol", "", nil)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		match.SetReady(name)
+	}
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start second round: %v", err)
+	}
+	if room.Match != match {
+		t.Error("expected the same match to continue into round 2")
+	}
+	if got := room.Engine.CurrentTurn(); got != "carol" {
+		t.Errorf("expected previous loser carol to start, got %q", got)
+	}
+
+	// Once the match is decided, a new match opens with the owner again.
+	room.Status = "finished"
+	match.RecordRound("alice", "bob", "", nil)
+	if !match.Over() {
+		t.Fatal("expected alice to have won the match")
+	}
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start new match: %v", err)
+	}
+	if room.Match == match {
+		t.Error("expected a new match after the last one was decided")
+	}
+	if got := room.Engine.CurrentTurn(); got != "alice" {
+		t.Errorf("expected owner alice to start the new match, got %q", got)
+	}
+}
+
+func TestSaveAndLoadMatchResult(t *testing.T) {
+	tempDB := filepath.Join(t.TempDir(), "test_match.sqlite3")
+	t.Cleanup(func() { os.Remove(tempDB) })
+	server, err := New(tempDB, "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	room := &Room{Match: NewMatchState(3)}
+	server.recordMatchRound(room, "room", "alice", "bob", "r1", map[string]int{"alice": 2, "bob": 1})
+	server.recordMatchRound(room, "room", "alice", "bob", "r2", map[string]int{"alice": 3, "bob": 0})
+
+	got, err := server.loadMatchResult(room.Match.ID)
+	if err != nil {
+		t.Fatalf("load match result: %v", err)
+	}
+	if got.Winner != "alice" || !got.Finished {
+		t.Errorf("expected finished match won by alice, got winner=%q finished=%v", got.Winner, got.Finished)
+	}
+	if len(got.Rounds) != 2 || got.Rounds[1].ResultID != "r2" {
+		t.Errorf("unexpected rounds: %+v", got.Rounds)
+	}
+}
+
+func TestMatchResultHidesPrivateRounds(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_match_vis.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := &Room{Match: NewMatchState(3)}
+	var ids []string
+	for i, visibility := range []string{VisibilityPublic, VisibilityPrivate} {
+		id, err := server.saveGameResult(&GameResult{
+			RoomName: "room", Winner: "alice", MatchID: room.Match.ID, Round: i + 1, Visibility: visibility,
+		})
+		if err != nil {
+			t.Fatalf("save result: %v", err)
+		}
+		ids = append(ids, id)
+		server.recordMatchRound(room, "room", "alice", "bob", id, map[string]int{"alice": 1})
+	}
+
+	rounds := func(query string) []RoundResult {
+		rec := httptest.NewRecorder()
+		server.routes().ServeHTTP(rec, httptest.NewRequest("GET", "/api/matches/"+room.Match.ID+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected match result, got %d", rec.Code)
+		}
+		var got MatchResult
+		json.NewDecoder(rec.Body).Decode(&got)
+		return got.Rounds
+	}
+	if got := rounds(""); len(got) != 1 || got[0].ResultID != ids[0] {
+		t.Errorf("expected only the public round, got %+v", got)
+	}
+	if got := rounds("?token=forged"); len(got) != 1 {
+		t.Errorf("expected a forged token to be ignored, got %+v", got)
+	}
+	if got := rounds("?token=" + server.resultToken(ids[1])); len(got) != 2 {
+		t.Errorf("expected the owner token to reveal the private round, got %+v", got)
+	}
+}
@@ -0,0 +1,248 @@
+package srv
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metrics is the process-wide metrics registry exposed at /metrics.
+var metrics = newMetricsRegistry()
+
+// metricsRegistry holds all counters and histograms in Prometheus text format.
+// Gauges derived from room state are computed at scrape time.
+type metricsRegistry struct {
+	wsMessages        *counterVec
+	rateLimitRejected *counterVec
+	broadcastsDropped *counterVec
+	votes             *counterVec
+	gameDuration      *histogramVec
+	dbWriteLatency    *histogramVec
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		wsMessages: newCounterVec("shiritori_ws_messages_total",
+			"WebSocket messages received, by message type.", "type"),
+		rateLimitRejected: newCounterVec("shiritori_rate_limit_rejections_total",
+			"Messages rejected by the per-connection rate limiter.", "type", "disconnect"),
+		broadcastsDropped: newCounterVec("shiritori_broadcasts_dropped_total",
+			"Messages dropped because a player's send channel was full.", "path"),
+		votes: newCounterVec("shiritori_votes_total",
+			"Resolved votes, by vote type and outcome.", "type", "outcome"),
+		gameDuration: newHistogramVec("shiritori_game_duration_seconds",
+			"Duration of finished games.",
+			[]float64{30, 60, 120, 300, 600, 1200, 1800, 3600}),
+		dbWriteLatency: newHistogramVec("shiritori_db_write_seconds",
+			"Latency of database writes.",
+			[]float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}, "op"),
+	}
+}
+
+// messageTypeLabel bounds label cardinality for client-supplied message
+// types: anything without a configured rate limit becomes "other".
+func messageTypeLabel(msgType string) string {
+	if _, ok := defaultRateLimits[msgType]; ok {
+		return msgType
+	}
+	return "other"
+}
+
+// counterVec is a monotonically increasing counter partitioned by labels.
+type counterVec struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	labels []string
+	values map[string]float64 // key: label values joined by \xff
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// Inc increments the counter for the given label values.
+func (c *counterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter for the given label values.
+func (c *counterVec) Add(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[strings.Join(labelValues, "\xff")] += v
+}
+
+// Value returns the current value for the given label values.
+func (c *counterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[strings.Join(labelValues, "\xff")]
+}
+
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, key, "", ""), formatFloat(c.values[key]))
+	}
+}
+
+// histogramVec is a cumulative histogram partitioned by labels.
+type histogramVec struct {
+	mu      sync.Mutex
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // per bucket, non-cumulative
+	sum    float64
+	count  uint64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+}
+
+// Observe records a value for the given label values.
+func (h *histogramVec) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := strings.Join(labelValues, "\xff")
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+// ObserveSince records the seconds elapsed since start.
+func (h *histogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Count returns the number of observations for the given label values.
+func (h *histogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[strings.Join(labelValues, "\xff")]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *histogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	keys := make([]string, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := h.series[key]
+		var cum uint64
+		for i, b := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, key, "le", formatFloat(b)), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, key, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, key, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, key, "", ""), s.count)
+	}
+}
+
+// writeGauge writes a single gauge family with one sample per label value.
+func writeGauge(w io.Writer, name, help, label string, values map[string]float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	for _, key := range sortedKeys(values) {
+		labels := ""
+		if label != "" {
+			labels = formatLabels([]string{label}, key, "", "")
+		}
+		fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(values[key]))
+	}
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels renders {a="x",b="y"} from label names and a joined key,
+// optionally appending one extra label (used for histogram "le").
+func formatLabels(names []string, key, extraName, extraValue string) string {
+	var parts []string
+	if len(names) > 0 {
+		values := strings.Split(key, "\xff")
+		for i, n := range names {
+			v := ""
+			if i < len(values) {
+				v = values[i]
+			}
+			parts = append(parts, fmt.Sprintf(`%s="%s"`, n, escapeLabel(v)))
+		}
+	}
+	if extraName != "" {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, extraName, extraValue))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// HandleMetrics serves all metrics in the Prometheus text exposition format.
+func (s *Server) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	rooms := map[string]float64{"waiting": 0, "playing": 0, "finished": 0}
+	players := 0.0
+	for _, room := range s.Rooms.AllRooms() {
+		room.mu.Lock()
+		rooms[room.Status]++
+		players += float64(len(room.Players))
+		room.mu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeGauge(w, "shiritori_rooms", "Active rooms, by status.", "status", rooms)
+	writeGauge(w, "shiritori_connected_players", "Players currently in a room.", "", map[string]float64{"": players})
+	metrics.wsMessages.write(w)
+	metrics.rateLimitRejected.write(w)
+	metrics.broadcastsDropped.write(w)
+	metrics.votes.write(w)
+	metrics.gameDuration.write(w)
+	metrics.dbWriteLatency.write(w)
+}
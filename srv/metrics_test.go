@@ -0,0 +1,76 @@
+package srv
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	h := newHistogramVec("test_seconds", "test", []float64{1, 5}, "op")
+	h.Observe(0.5, "a")
+	h.Observe(3, "a")
+	h.Observe(10, "a")
+
+	var b strings.Builder
+	h.write(&b)
+	out := b.String()
+	for _, want := range []string{
+		`test_seconds_bucket{op="a",le="1"} 1`,
+		`test_seconds_bucket{op="a",le="5"} 2`,
+		`test_seconds_bucket{op="a",le="+Inf"} 3`,
+		`test_seconds_sum{op="a"} 13.5`,
+		`test_seconds_count{op="a"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestMessageTypeLabelBoundsCardinality(t *testing.T) {
+	if got := messageTypeLabel("answer"); got != "answer" {
+		t.Errorf("expected answer, got %q", got)
+	}
+	if got := messageTypeLabel("made_up_type_123"); got != "other" {
+		t.Errorf("expected other, got %q", got)
+	}
+}
+
+func TestHandleMetrics(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_metrics.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("m1", RoomSettings{Name: "m"})
+	room.AddPlayer(&Player{Name: "alice", Send: make(chan []byte, 256)})
+	room.AddPlayer(&Player{Name: "bob", Send: make(chan []byte, 256)})
+
+	// A full send channel counts as a dropped broadcast.
+	full := &Player{Name: "carol", Send: make(chan []byte)}
+	room.AddPlayer(full)
+	before := metrics.broadcastsDropped.Value("broadcast")
+	room.Broadcast([]byte(`{}`))
+	if metrics.broadcastsDropped.Value("broadcast") != before+1 {
+		t.Error("expected one dropped broadcast to be counted")
+	}
+
+	if _, err := server.saveGameResult(&GameResult{RoomName: "m"}); err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	server.HandleMetrics(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+	for _, want := range []string{
+		`shiritori_rooms{status="waiting"} 1`,
+		`shiritori_connected_players 3`,
+		`# TYPE shiritori_broadcasts_dropped_total counter`,
+		`shiritori_db_write_seconds_count{op="save_game_result"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in metrics output", want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package srv
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// combiningDakuten is the combining voiced sound mark (U+3099).
+const combiningDakuten = '\u3099'
+
+// spacingMarks turns the spacing voiced sound marks (゛゜), which some IMEs
+// and keyboards type after a kana, into their combining forms so NFKC can
+// compose them with the kana before.
+var spacingMarks = strings.NewReplacer("゛", "\u3099", "゜", "\u309a")
+
+// voicedWa maps the katakana with dakuten that have no hiragana counterpart
+// to the ヴ spelling they are read as.
+var voicedWa = map[rune]string{
+	'ヷ': "ヴァ",
+	'ヸ': "ヴィ",
+	'ヹ': "ヴェ",
+	'ヺ': "ヴォ",
+}
+
+// normalizeWord brings a word as typed into the single form the game
+// validates and stores. It trims surrounding whitespace, folds width
+// (half-width ｼﾘﾄﾘ becomes シリトリ) and ligatures (ゟ becomes より) with NFKC,
+// composes combining or spacing dakuten with the kana before them, expands
+// the iteration marks ゝゞヽヾ, and spells ヷヸヹヺ with ヴ. Katakana stays
+// katakana; toHiragana folds it. Anything that can't be normalized is left in
+// place for validation to reject.
+func normalizeWord(s string) string {
+	s = strings.TrimSpace(norm.NFKC.String(spacingMarks.Replace(s)))
+	if !strings.ContainsAny(s, "ゝゞヽヾヷヸヹヺ") {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	var prev rune
+	for _, r := range s {
+		switch r {
+		case 'ゝ', 'ヽ':
+			if v, ok := withMark(prev, 0); ok {
+				r = v
+			}
+		case 'ゞ', 'ヾ':
+			if v, ok := withMark(prev, combiningDakuten); ok {
+				r = v
+			}
+		}
+		if v, ok := voicedWa[r]; ok {
+			b.WriteString(v)
+			prev, _ = utf8.DecodeLastRuneInString(v)
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String()
+}
+
+// withMark returns kana r with its voicing replaced by mark: 0 for the plain
+// kana, or a combining (han)dakuten. It reports false if no such kana exists.
+func withMark(r, mark rune) (rune, bool) {
+	if r == 0 {
+		return 0, false
+	}
+	base, _ := utf8.DecodeRuneInString(norm.NFD.String(string(r)))
+	if !isHiragana(base) && !isKatakana(base) {
+		return 0, false
+	}
+	if mark == 0 {
+		return base, true
+	}
+	composed := norm.NFC.String(string([]rune{base, mark}))
+	if utf8.RuneCountInString(composed) != 1 {
+		return 0, false
+	}
+	v, _ := utf8.DecodeRuneInString(composed)
+	return v, true
+}
@@ -0,0 +1,180 @@
+package srv
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNormalizeWord(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"しりとり", "しりとり"},
+		{"  りんご　", "りんご"},
+		// Half-width katakana, with and without voiced marks.
+		{"ｼﾘﾄﾘ", "シリトリ"},
+		{"ｶﾞｯｺｳ", "ガッコウ"},
+		{"ﾊﾟﾝﾀﾞ", "パンダ"},
+		{"ｽｰﾊﾟｰ", "スーパー"},
+		{"ｳﾞｧｲｵﾘﾝ", "ヴァイオリン"},
+		// Combining and spacing dakuten compose with the kana before.
+		{"がっこう", "がっこう"},
+		{"ぱんだ", "ぱんだ"},
+		{"か゛っこう", "がっこう"},
+		{"は゜んだ", "ぱんだ"},
+		{"ウ゛ァイオリン", "ヴァイオリン"},
+		{"あ゛", "あ゙"}, // no voiced あ: left for validation to reject
+		// Iteration marks repeat the kana before, voiced or not.
+		{"こゝろ", "こころ"},
+		{"いすゞ", "いすず"},
+		{"ぶゝ", "ぶふ"},
+		{"かゝゝ", "かかか"},
+		{"バヽ", "バハ"},
+		{"ハヾ", "ハバ"},
+		{"あゞ", "あゞ"},
+		{"ゝ", "ゝ"},
+		// Ligatures and katakana with no hiragana counterpart.
+		{"ゟ", "より"},
+		{"ヿ", "コト"},
+		{"ヷイン", "ヴァイン"},
+		{"ヸ", "ヴィ"},
+		{"ヹ", "ヴェ"},
+		{"ヺ", "ヴォ"},
+	}
+	for _, tt := range tests {
+		if got := normalizeWord(tt.input); got != tt.want {
+			t.Errorf("normalizeWord(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizedReading(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"ｼﾘﾄﾘ", "しりとり"},
+		{"ヴァイオリン", "ゔぁいおりん"},
+		{"ヷイン", "ゔぁいん"},
+		{"イスヾ", "いすず"},
+		{"ヵ", "ゕ"},
+		{"ヶ", "ゖ"},
+	}
+	for _, tt := range tests {
+		if got := toHiragana(normalizeWord(tt.input)); got != tt.want {
+			t.Errorf("toHiragana(normalizeWord(%q)) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestIsJapaneseRejectsMarks(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"しりとり", true},
+		{"らーめん", true},
+		{"ゔぁいおりん", true},
+		{"ゐど", true},
+		{"ー", false},
+		{"ーー", false},
+		{"ーあ", false},
+		{"ゝ", false},
+		{"ゞ", false},
+		{"ゟ", false}, // only after normalization
+		{"あ゙", false},
+		{"゛", false},
+		{"・", false},
+		{"ヽ", false},
+		{"゗", false}, // unassigned
+		{"かん字", false},
+	}
+	for _, tt := range tests {
+		if got := isJapanese(tt.input); got != tt.want {
+			t.Errorf("isJapanese(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestVuIsDakuten(t *testing.T) {
+	if !IsDakuten('ゔ') || GetKanaRow('ゔ') != "あ行" {
+		t.Error("expected ゔ to be a voiced kana of the あ row")
+	}
+	if ValidateNoDakuten(toHiragana("ヴィラ")) != 'ゔ' {
+		t.Error("expected ヴ to break the no-dakuten rule")
+	}
+	if getLastChar(toHiragana(normalizeWord("イスヾ"))) != 'ず' {
+		t.Error("expected a word to end on the kana its iteration mark repeats")
+	}
+}
+
+// TestKanaBlocks checks every code point of the kana and half-width katakana
+// blocks: each either normalizes to a word made of hiragana letters (and ー)
+// that all belong to a kana row, or is rejected.
+func TestKanaBlocks(t *testing.T) {
+	check := func(lo, hi rune) {
+		for r := lo; r <= hi; r++ {
+			checkNormalizedKana(t, string(r))
+		}
+	}
+	check(0x3040, 0x30FF)
+	check(0xFF61, 0xFF9F)
+
+	for r := rune(0x30A1); r <= 0x30FA; r++ {
+		if !isJapanese(normalizeWord(string(r))) {
+			t.Errorf("katakana %q should be accepted", string(r))
+		}
+	}
+	for r := rune(0xFF66); r <= 0xFF9D; r++ {
+		if r == 0xFF70 {
+			continue // ｰ alone is just a long vowel mark
+		}
+		if !isJapanese(normalizeWord(string(r))) {
+			t.Errorf("half-width katakana %q should be accepted", string(r))
+		}
+	}
+}
+
+// checkNormalizedKana asserts the invariants of the normalization pipeline
+// for one input.
+func checkNormalizedKana(t *testing.T, s string) {
+	t.Helper()
+	n := normalizeWord(s)
+	if again := normalizeWord(n); again != n {
+		t.Errorf("normalizeWord not idempotent for %q: %q then %q", s, n, again)
+	}
+	h := toHiragana(n)
+	if toHiragana(h) != h {
+		t.Errorf("toHiragana not idempotent for %q: %q", s, h)
+	}
+	if isJapanese(n) != isJapanese(h) {
+		t.Errorf("%q: katakana %q and hiragana %q disagree on validity", s, n, h)
+	}
+	if !isJapanese(h) {
+		return
+	}
+	for _, r := range h {
+		if isLongVowelMark(r) {
+			continue
+		}
+		if !isHiragana(r) {
+			t.Errorf("%q: %q contains non-hiragana %U", s, h, r)
+		} else if GetKanaRow(r) == "" {
+			t.Errorf("%q: %q has no kana row", s, string(r))
+		}
+	}
+	if last := getLastChar(h); !isHiragana(last) {
+		t.Errorf("%q: last char %U of %q is not a hiragana letter", s, last, h)
+	}
+}
+
+func FuzzNormalizeWord(f *testing.F) {
+	for _, s := range []string{"しりとり", "ｼﾘﾄﾘ", "か゛", "いすゞ", "ヷ", "ゟ", "ー", "ゝゞ", "あ゙", " ｶﾞｰ "} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if !utf8.ValidString(s) {
+			return
+		}
+		checkNormalizedKana(t, s)
+	})
+}
@@ -6,19 +6,24 @@ import (
 	"strings"
 )
 
-// HandleOGPImage generates an SVG OGP image for a game result.
-func (s *Server) HandleOGPImage(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("id")
-	if id == "" {
-		http.NotFound(w, r)
-		return
-	}
-	result, err := s.loadResult(id)
-	if err != nil {
-		http.NotFound(w, r)
-		return
-	}
+// ogpScore is one row of the score table on an OGP card.
+type ogpScore struct {
+	Name  string
+	Score int
+	Medal string
+}
 
+// ogpCard is the content of a result's OGP image, shared by the SVG and
+// PNG renderers.
+type ogpCard struct {
+	Title      string
+	Genre      string
+	Scores     []ogpScore
+	ChainLines []string
+}
+
+// newOGPCard lays out the content of a result's OGP image.
+func newOGPCard(result *GameResult) ogpCard {
 	// Build word chain
 	words := make([]string, len(result.History))
 	for i, h := range result.History {
@@ -31,16 +36,6 @@ func (s *Server) HandleOGPImage(w http.ResponseWriter, r *http.Request) {
 		title = fmt.Sprintf("%sさんの勝利！（%d語）", result.Winner, len(result.History))
 	}
 
-	// Build chain lines (wrap at ~18 chars per line, max 4 lines)
-	chainLines := wrapChain(words, 16, 4)
-
-	// Build scores
-	type scoreEntry struct {
-		Name  string
-		Score int
-		Medal string
-	}
-	var scores []scoreEntry
 	// Sort by score desc
 	type kv struct {
 		K string
@@ -58,18 +53,48 @@ func (s *Server) HandleOGPImage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	medals := []string{"🥇", "🥈", "🥉"}
+	var scores []ogpScore
 	for i, kv := range sorted {
 		m := ""
 		if i < len(medals) {
 			m = medals[i]
 		}
-		scores = append(scores, scoreEntry{kv.K, kv.V, m})
+		scores = append(scores, ogpScore{kv.K, kv.V, m})
+	}
+
+	genre := ""
+	if result.Genre != "" && result.Genre != "なし" {
+		genre = result.Genre
 	}
 
+	return ogpCard{
+		Title:  title,
+		Genre:  genre,
+		Scores: scores,
+		// Build chain lines (wrap at ~18 chars per line, max 4 lines)
+		ChainLines: wrapChain(words, 16, 4),
+	}
+}
+
+// HandleOGPImage generates an SVG OGP image for a game result.
+func (s *Server) HandleOGPImage(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id == "" {
+		http.NotFound(w, r)
+		return
+	}
+	result, err := s.loadVisibleResult(r, id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	card := newOGPCard(result)
+	title, scores, chainLines := card.Title, card.Scores, card.ChainLines
+
 	// Genre tag
 	genreTag := ""
-	if result.Genre != "" && result.Genre != "なし" {
-		genreTag = fmt.Sprintf(`<text x="600" y="68" text-anchor="end" font-size="18" fill="#818cf8" font-weight="500">ジャンル: %s</text>`, svgEsc(result.Genre))
+	if card.Genre != "" {
+		genreTag = fmt.Sprintf(`<text x="600" y="68" text-anchor="end" font-size="18" fill="#818cf8" font-weight="500">ジャンル: %s</text>`, svgEsc(card.Genre))
 	}
 
 	// Build score rows SVG
@@ -137,10 +162,18 @@ func (s *Server) HandleOGPImage(w http.ResponseWriter, r *http.Request) {
 		svgEsc(title), genreTag, scoreRows, chainSVG)
 
 	w.Header().Set("Content-Type", "image/svg+xml")
-	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("Cache-Control", resultImageCacheControl(result))
 	w.Write([]byte(svg))
 }
 
+// resultImageCacheControl lets shared caches keep images of results anyone may see.
+func resultImageCacheControl(result *GameResult) string {
+	if result.Visibility == VisibilityPrivate {
+		return "private, no-store"
+	}
+	return "public, max-age=86400"
+}
+
 func svgEsc(s string) string {
 	s = strings.ReplaceAll(s, "&", "&amp;")
 	s = strings.ReplaceAll(s, "<", "&lt;")
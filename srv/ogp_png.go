@@ -0,0 +1,376 @@
+package srv
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// The PNG card is drawn in the SVG card's 640x330 coordinate space, scaled
+// to the 1200x630 size social sites expect.
+const (
+	ogpWidth  = 1200
+	ogpHeight = 630
+	ogpScale  = float32(ogpWidth) / 640
+)
+
+// ogpOffsetY centres the scaled 330-unit-high card vertically, as an SVG
+// viewer does for the SVG card.
+var ogpOffsetY = (ogpHeight - 330*ogpScale) / 2
+
+// ogpCacheSize bounds how many rendered PNGs are kept in memory.
+const ogpCacheSize = 256
+
+var (
+	ogpFontOnce sync.Once
+	ogpFont     *opentype.Font
+	ogpFontErr  error
+)
+
+// loadOGPFont parses the embedded font once. Faces made from it are not safe
+// for concurrent use, so each render creates its own.
+func loadOGPFont() (*opentype.Font, error) {
+	ogpFontOnce.Do(func() {
+		ogpFont, ogpFontErr = opentype.Parse(ogpFontTTF)
+	})
+	return ogpFont, ogpFontErr
+}
+
+// ogpImageCache keeps the most recently served PNGs by result ID. A result's
+// content never changes after it is saved, so entries only leave the cache
+// when it is full or the result is deleted.
+type ogpImageCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // front is most recently used; values are result IDs
+	entries map[string]*ogpCacheEntry
+}
+
+type ogpCacheEntry struct {
+	png  []byte
+	elem *list.Element
+}
+
+func newOGPImageCache(max int) *ogpImageCache {
+	return &ogpImageCache{
+		max:     max,
+		order:   list.New(),
+		entries: make(map[string]*ogpCacheEntry),
+	}
+}
+
+// Get returns the cached PNG for a result, if any.
+func (c *ogpImageCache) Get(id string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e.elem)
+	return e.png, true
+}
+
+// Add stores a PNG, evicting the least recently used one when full.
+func (c *ogpImageCache) Add(id string, png []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[id]; ok {
+		e.png = png
+		c.order.MoveToFront(e.elem)
+		return
+	}
+	c.entries[id] = &ogpCacheEntry{png: png, elem: c.order.PushFront(id)}
+	for c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(string))
+	}
+}
+
+// Remove drops a result's PNG, e.g. after the result is deleted.
+func (c *ogpImageCache) Remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[id]; ok {
+		c.order.Remove(e.elem)
+		delete(c.entries, id)
+	}
+}
+
+// HandleOGPPNG serves a result's OGP card as a PNG, which unlike the SVG is
+// accepted by every major social site.
+func (s *Server) HandleOGPPNG(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	// Always check visibility first so the cache never leaks a private card.
+	result, err := s.loadVisibleResult(r, id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	data, ok := s.ogpImages.Get(id)
+	if !ok {
+		data, err = renderOGPPNG(newOGPCard(result))
+		if err != nil {
+			slog.Error("render ogp png", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		s.ogpImages.Add(id, data)
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", resultImageCacheControl(result))
+	w.Write(data)
+}
+
+// Card colours, matching the SVG card.
+var (
+	ogpGradientFrom = color.RGBA{0x63, 0x66, 0xf1, 0xff}
+	ogpGradientTo   = color.RGBA{0x81, 0x8c, 0xf8, 0xff}
+	ogpCardColor    = color.NRGBA{0xff, 0xff, 0xff, 0xf7}
+	ogpTitleColor   = color.RGBA{0x1e, 0x1b, 0x4b, 0xff}
+	ogpGenreColor   = color.RGBA{0x81, 0x8c, 0xf8, 0xff}
+	ogpLabelColor   = color.RGBA{0x6b, 0x72, 0x80, 0xff}
+	ogpDividerColor = color.RGBA{0xe5, 0xe7, 0xeb, 0xff}
+	ogpRowColor     = color.RGBA{0xf1, 0xf0, 0xfb, 0xff}
+	ogpFirstColor   = color.RGBA{0xfe, 0xf3, 0xc7, 0xff}
+	ogpScoreColor   = color.RGBA{0x63, 0x66, 0xf1, 0xff}
+	ogpChainColor   = color.RGBA{0x4f, 0x46, 0xe5, 0xff}
+	ogpFooterColor  = color.RGBA{0xa5, 0xb4, 0xfc, 0xff}
+	// The font has no emoji, so medals are drawn as numbered discs.
+	ogpMedalColors = []color.RGBA{
+		{0xf5, 0x9e, 0x0b, 0xff},
+		{0x9c, 0xa3, 0xaf, 0xff},
+		{0xb4, 0x53, 0x09, 0xff},
+	}
+)
+
+// textAnchor mirrors SVG's text-anchor attribute.
+type textAnchor int
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// ogpCanvas draws in card units onto the scaled PNG.
+type ogpCanvas struct {
+	img   *image.RGBA
+	font  *opentype.Font
+	faces map[float64]font.Face
+}
+
+// renderOGPPNG draws the same card as HandleOGPImage and encodes it as PNG.
+func renderOGPPNG(card ogpCard) ([]byte, error) {
+	f, err := loadOGPFont()
+	if err != nil {
+		return nil, fmt.Errorf("parse ogp font: %w", err)
+	}
+	c := &ogpCanvas{
+		img:   image.NewRGBA(image.Rect(0, 0, ogpWidth, ogpHeight)),
+		font:  f,
+		faces: make(map[float64]font.Face),
+	}
+	defer c.close()
+
+	c.gradient(ogpGradientFrom, ogpGradientTo)
+	c.roundRect(16, 16, 608, 298, 16, ogpCardColor)
+
+	if err := c.text(320, 60, 24, true, anchorMiddle, ogpTitleColor, card.Title); err != nil {
+		return nil, err
+	}
+	if card.Genre != "" {
+		if err := c.text(600, 68, 18, false, anchorEnd, ogpGenreColor, "ジャンル: "+card.Genre); err != nil {
+			return nil, err
+		}
+	}
+	for y := float32(90); y < 290; y += 8 {
+		c.rect(320-0.5, y, 1, 4, ogpDividerColor)
+	}
+
+	if err := c.text(165, 118, 14, true, anchorMiddle, ogpLabelColor, "スコア"); err != nil {
+		return nil, err
+	}
+	for i, sc := range card.Scores {
+		if i >= 4 {
+			break
+		}
+		y := float32(130 + i*36)
+		bg := ogpRowColor
+		if i == 0 {
+			bg = ogpFirstColor
+		}
+		c.roundRect(40, y, 250, 30, 6, bg)
+		if sc.Medal != "" && i < len(ogpMedalColors) {
+			c.circle(64, y+15, 9, ogpMedalColors[i])
+			if err := c.text(64, y+19.5, 12, true, anchorMiddle, color.White, strconv.Itoa(i+1)); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.text(82, y+21, 15, true, anchorStart, ogpTitleColor, sc.Name); err != nil {
+			return nil, err
+		}
+		if err := c.text(270, y+21, 15, true, anchorEnd, ogpScoreColor, fmt.Sprintf("%d点", sc.Score)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.text(480, 118, 14, true, anchorMiddle, ogpLabelColor, "しりとりチェーン"); err != nil {
+		return nil, err
+	}
+	for i, line := range card.ChainLines {
+		if err := c.text(480, float32(145+i*28), 15, false, anchorMiddle, ogpChainColor, line); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.text(320, 310, 12, false, anchorMiddle, ogpFooterColor, "しりとり - マルチプレイヤー"); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, fmt.Errorf("encode ogp png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *ogpCanvas) close() {
+	for _, face := range c.faces {
+		face.Close()
+	}
+}
+
+// px converts a card-space point to image pixels.
+func (c *ogpCanvas) px(x, y float32) (float32, float32) {
+	return x * ogpScale, y*ogpScale + ogpOffsetY
+}
+
+// gradient fills the whole image with a top-left to bottom-right gradient,
+// like an SVG linearGradient from (0,0) to (1,1) in bounding-box units.
+func (c *ogpCanvas) gradient(from, to color.RGBA) {
+	lerp := func(a, b uint8, t float64) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+	}
+	for y := 0; y < ogpHeight; y++ {
+		for x := 0; x < ogpWidth; x++ {
+			t := (float64(x)/ogpWidth + float64(y)/ogpHeight) / 2
+			c.img.SetRGBA(x, y, color.RGBA{
+				lerp(from.R, to.R, t), lerp(from.G, to.G, t), lerp(from.B, to.B, t), 0xff,
+			})
+		}
+	}
+}
+
+func (c *ogpCanvas) fill(z *vector.Rasterizer, col color.Color) {
+	z.Draw(c.img, c.img.Bounds(), image.NewUniform(col), image.Point{})
+}
+
+func (c *ogpCanvas) rect(x, y, w, h float32, col color.Color) {
+	z := vector.NewRasterizer(ogpWidth, ogpHeight)
+	x0, y0 := c.px(x, y)
+	x1, y1 := c.px(x+w, y+h)
+	z.MoveTo(x0, y0)
+	z.LineTo(x1, y0)
+	z.LineTo(x1, y1)
+	z.LineTo(x0, y1)
+	z.ClosePath()
+	c.fill(z, col)
+}
+
+// kappa places cubic Bézier control points so a quarter turn approximates a
+// circular arc.
+const kappa = 0.5523
+
+func (c *ogpCanvas) roundRect(x, y, w, h, r float32, col color.Color) {
+	z := vector.NewRasterizer(ogpWidth, ogpHeight)
+	x0, y0 := c.px(x, y)
+	x1, y1 := c.px(x+w, y+h)
+	r *= ogpScale
+	k := r * kappa
+	z.MoveTo(x0+r, y0)
+	z.LineTo(x1-r, y0)
+	z.CubeTo(x1-r+k, y0, x1, y0+r-k, x1, y0+r)
+	z.LineTo(x1, y1-r)
+	z.CubeTo(x1, y1-r+k, x1-r+k, y1, x1-r, y1)
+	z.LineTo(x0+r, y1)
+	z.CubeTo(x0+r-k, y1, x0, y1-r+k, x0, y1-r)
+	z.LineTo(x0, y0+r)
+	z.CubeTo(x0, y0+r-k, x0+r-k, y0, x0+r, y0)
+	z.ClosePath()
+	c.fill(z, col)
+}
+
+func (c *ogpCanvas) circle(cx, cy, r float32, col color.Color) {
+	z := vector.NewRasterizer(ogpWidth, ogpHeight)
+	x, y := c.px(cx, cy)
+	r *= ogpScale
+	k := r * kappa
+	z.MoveTo(x+r, y)
+	z.CubeTo(x+r, y+k, x+k, y+r, x, y+r)
+	z.CubeTo(x-k, y+r, x-r, y+k, x-r, y)
+	z.CubeTo(x-r, y-k, x-k, y-r, x, y-r)
+	z.CubeTo(x+k, y-r, x+r, y-k, x+r, y)
+	z.ClosePath()
+	c.fill(z, col)
+}
+
+func (c *ogpCanvas) face(size float32) (font.Face, error) {
+	px := float64(size * ogpScale)
+	if face, ok := c.faces[px]; ok {
+		return face, nil
+	}
+	face, err := opentype.NewFace(c.font, &opentype.FaceOptions{
+		Size:    px,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create ogp font face: %w", err)
+	}
+	c.faces[px] = face
+	return face, nil
+}
+
+// text draws s with its baseline at card-space (x, y). The embedded font has
+// a single weight, so bold text is drawn twice with a one-pixel offset.
+func (c *ogpCanvas) text(x, y, size float32, bold bool, anchor textAnchor, col color.Color, s string) error {
+	face, err := c.face(size)
+	if err != nil {
+		return err
+	}
+	d := &font.Drawer{Dst: c.img, Src: image.NewUniform(col), Face: face}
+	px, py := c.px(x, y)
+	width := d.MeasureString(s)
+	if bold {
+		width += fixed.I(1)
+	}
+	dot := fixed.Point26_6{X: fixed.Int26_6(px * 64), Y: fixed.Int26_6(py * 64)}
+	switch anchor {
+	case anchorMiddle:
+		dot.X -= width / 2
+	case anchorEnd:
+		dot.X -= width
+	}
+	d.Dot = dot
+	d.DrawString(s)
+	if bold {
+		d.Dot = dot.Add(fixed.P(1, 0))
+		d.DrawString(s)
+	}
+	return nil
+}
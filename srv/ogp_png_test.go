@@ -0,0 +1,75 @@
+package srv
+
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestOGPPNGRendersAndCaches(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_ogp.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	id, err := server.saveGameResult(&GameResult{
+		RoomName: "ogp",
+		Genre:    "食べ物",
+		Winner:   "alice",
+		Scores:   map[string]int{"alice": 12, "bob": 7, "carol": 3},
+		History: []WordEntry{
+			{Word: "しりとり", Player: "alice"},
+			{Word: "りんご", Player: "bob"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /results/{id}/ogp.png", server.HandleOGPPNG)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	rec := get("/results/" + id + "/ogp.png")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expected a PNG, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != ogpWidth || b.Dy() != ogpHeight {
+		t.Errorf("expected %dx%d image, got %v", ogpWidth, ogpHeight, b)
+	}
+	cached, ok := server.ogpImages.Get(id)
+	if !ok || !bytes.Equal(cached, rec.Body.Bytes()) {
+		t.Error("expected the rendered PNG to be cached")
+	}
+
+	// A deleted result must not be served from the cache.
+	if _, err := server.deleteGameResult(id); err != nil {
+		t.Fatalf("delete result: %v", err)
+	}
+	if rec := get("/results/" + id + "/ogp.png"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestOGPImageCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newOGPImageCache(2)
+	c.Add("a", []byte("a"))
+	c.Add("b", []byte("b"))
+	c.Get("a")
+	c.Add("c", []byte("c"))
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected recently used entry to be kept")
+	}
+}
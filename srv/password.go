@@ -0,0 +1,126 @@
+package srv
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const (
+	// passwordIterations is the PBKDF2 iteration count for room passwords.
+	passwordIterations = 100_000
+	// passwordSaltLen is the length in bytes of the random per-room salt.
+	passwordSaltLen = 16
+	// passwordKeyLen is the length in bytes of the derived key.
+	passwordKeyLen = 32
+)
+
+// joinFailureLimit throttles wrong room passwords and unknown room IDs per
+// client IP. It is never keyed on the room alone, so failed guesses can't lock
+// other players out of a room.
+var joinFailureLimit = RateLimitConfig{Rate: 0.1, Burst: 5}
+
+// roomPassword is a salted PBKDF2 hash of a room password.
+type roomPassword struct {
+	salt []byte
+	hash []byte
+}
+
+// hashRoomPassword derives a salted hash for a room password.
+func hashRoomPassword(password string) (*roomPassword, error) {
+	salt := make([]byte, passwordSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	hash, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	return &roomPassword{salt: salt, hash: hash}, nil
+}
+
+// matches reports whether password matches the stored hash in constant time.
+func (rp *roomPassword) matches(password string) bool {
+	hash, err := pbkdf2.Key(sha256.New, password, rp.salt, passwordIterations, passwordKeyLen)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hash, rp.hash) == 1
+}
+
+// defaultTrustedProxies are the proxies whose X-Forwarded-For is believed
+// when none are configured: a reverse proxy on the same host.
+var defaultTrustedProxies = []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+}
+
+// SetTrustedProxies configures which peers (IPs or CIDRs) are proxies whose
+// X-Forwarded-For header is believed.
+func (s *Server) SetTrustedProxies(entries []string) error {
+	var proxies []netip.Prefix
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if !strings.Contains(e, "/") {
+			addr, err := netip.ParseAddr(e)
+			if err != nil {
+				return fmt.Errorf("trusted proxy %q: %w", e, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(e)
+		if err != nil {
+			return fmt.Errorf("trusted proxy %q: %w", e, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	s.trustedProxies = proxies
+	return nil
+}
+
+// clientIP returns the client address for a request. X-Forwarded-For is only
+// believed when the peer is a trusted proxy, and then only its right-most
+// entry, which that proxy appended; entries to its left are client-supplied.
+func (s *Server) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !s.trustedProxy(peer.Unmap()) {
+		return host
+	}
+	fwd := r.Header.Values("X-Forwarded-For")
+	if len(fwd) == 0 {
+		return host
+	}
+	entries := strings.Split(fwd[len(fwd)-1], ",")
+	if last := strings.TrimSpace(entries[len(entries)-1]); last != "" {
+		return last
+	}
+	return host
+}
+
+// trustedProxy reports whether peer is a configured trusted proxy.
+func (s *Server) trustedProxy(peer netip.Addr) bool {
+	proxies := s.trustedProxies
+	if proxies == nil {
+		proxies = defaultTrustedProxies
+	}
+	for _, p := range proxies {
+		if p.Contains(peer) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRoomPasswordHashedAndCleared(t *testing.T) {
+	rm := NewRoomManager()
+	room := rm.CreateRoom("r1", RoomSettings{Name: "secret", Password: "hunter2"})
+
+	if room.Settings.Password != "" {
+		t.Fatal("expected plaintext password to be cleared from settings")
+	}
+	if !room.Settings.HasPassword {
+		t.Fatal("expected HasPassword to be set")
+	}
+
+	state, err := json.Marshal(room.GetState())
+	if err != nil {
+		t.Fatalf("marshal state: %v", err)
+	}
+	if strings.Contains(string(state), "hunter2") {
+		t.Error("room_state must not contain the password")
+	}
+
+	if room.CheckPassword("wrong") {
+		t.Error("expected wrong password to be rejected")
+	}
+	if !room.CheckPassword("hunter2") {
+		t.Error("expected correct password to be accepted")
+	}
+}
+
+func TestRoomWithoutPasswordAcceptsAnything(t *testing.T) {
+	rm := NewRoomManager()
+	room := rm.CreateRoom("r1", RoomSettings{Name: "open", HasPassword: true})
+	if room.Settings.HasPassword {
+		t.Fatal("HasPassword from the client must be ignored without a password")
+	}
+	if !room.CheckPassword("") {
+		t.Error("expected open room to accept an empty password")
+	}
+}
+
+func TestUpdateSettingsKeepsPassword(t *testing.T) {
+	rm := NewRoomManager()
+	room := rm.CreateRoom("r1", RoomSettings{Name: "secret", Password: "hunter2"})
+
+	if err := room.UpdateSettings(RoomSettings{MinLen: 3}); err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+	if !room.Settings.HasPassword || !room.CheckPassword("hunter2") {
+		t.Error("expected password to survive a settings update without a new password")
+	}
+
+	if err := room.UpdateSettings(RoomSettings{Password: "swordfish"}); err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+	if room.CheckPassword("hunter2") || !room.CheckPassword("swordfish") {
+		t.Error("expected new password to replace the old one")
+	}
+
+	if err := room.UpdateSettings(RoomSettings{ClearPassword: true}); err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+	if room.Settings.HasPassword || room.Settings.ClearPassword || !room.CheckPassword("") {
+		t.Errorf("expected the password to be removed, got %+v", room.Settings)
+	}
+}
+
+func TestFailureLimiterBlocksAfterBurst(t *testing.T) {
+	fl := NewFailureLimiter(RateLimitConfig{Rate: 0.01, Burst: 3})
+	for i := 0; i < 3; i++ {
+		if fl.Blocked("room:r1") {
+			t.Fatalf("blocked too early after %d failures", i)
+		}
+		fl.RecordFailure("room:r1", "ip:1.2.3.4")
+	}
+	if !fl.Blocked("room:r1") {
+		t.Error("expected room to be blocked after burst of failures")
+	}
+	if !fl.Blocked("room:other", "ip:1.2.3.4") {
+		t.Error("expected client IP to be blocked on any room")
+	}
+	if fl.Blocked("room:r2", "ip:5.6.7.8") {
+		t.Error("unrelated keys should not be blocked")
+	}
+}
+
+func TestWrongPasswordsDoNotLockOutRoom(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_join.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("pw01", RoomSettings{Name: "secret", Password: "hunter2"})
+	server.setUpRoom(room)
+
+	for range 10 {
+		server.handleJoinRoom(nil, "mallory", "pw01", "guess", "198.51.100.1")
+	}
+	if _, _, err := server.handleJoinRoom(nil, "mallory", "pw01", "hunter2", "198.51.100.1"); err == nil {
+		t.Error("expected the guessing client to be throttled")
+	}
+	if _, _, err := server.handleJoinRoom(nil, "alice", "pw01", "hunter2", "203.0.113.7"); err != nil {
+		t.Errorf("expected another client to join with the right password, got %v", err)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	s := &Server{}
+	for _, tc := range []struct {
+		remote, forwarded, want string
+	}{
+		{"203.0.113.5:1234", "", "203.0.113.5"},
+		{"203.0.113.5:1234", "1.2.3.4", "203.0.113.5"},
+		{"127.0.0.1:5000", "", "127.0.0.1"},
+		{"127.0.0.1:5000", "198.51.100.7", "198.51.100.7"},
+		{"127.0.0.1:5000", "6.6.6.6, 198.51.100.7", "198.51.100.7"},
+		{"[::1]:5000", "198.51.100.7", "198.51.100.7"},
+	} {
+		r := httptest.NewRequest("GET", "/ws", nil)
+		r.RemoteAddr = tc.remote
+		if tc.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tc.forwarded)
+		}
+		if got := s.clientIP(r); got != tc.want {
+			t.Errorf("%s via %q: got %s, want %s", tc.remote, tc.forwarded, got, tc.want)
+		}
+	}
+
+	if err := s.SetTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"}); err != nil {
+		t.Fatalf("set trusted proxies: %v", err)
+	}
+	r := httptest.NewRequest("GET", "/ws", nil)
+	r.Header.Set("X-Forwarded-For", "6.6.6.6, 198.51.100.7")
+	for remote, want := range map[string]string{
+		"10.1.2.3:80":    "198.51.100.7",
+		"192.0.2.1:80":   "198.51.100.7",
+		"127.0.0.1:5000": "127.0.0.1",
+	} {
+		r.RemoteAddr = remote
+		if got := s.clientIP(r); got != want {
+			t.Errorf("%s: got %s, want %s", remote, got, want)
+		}
+	}
+	if err := s.SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Error("expected a bad proxy entry to be refused")
+	}
+}
@@ -0,0 +1,84 @@
+package srv
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRaceMode(t *testing.T) {
+	ge := NewGameEngine(RoomSettings{Race: true}, []string{"alice", "bob", "carol"}, nil)
+	ge.Open()
+	if ge.CurrentTurn() != "" {
+		t.Errorf("expected no current turn in a race, got %q", ge.CurrentTurn())
+	}
+	if res, msg := ge.SubmitAnswer("ねこ", "dave", 0, false); res != ValidateRejected {
+		t.Errorf("expected an outsider to be turned away, got %v %q", res, msg)
+	}
+	ge.PromptAt = time.Now().Add(-1500 * time.Millisecond)
+	// Anyone may answer, not just the first player in turn order.
+	if res, msg := ge.SubmitAnswer("ねこ", "bob", 0, false); res != ValidateOK {
+		t.Fatalf("expected bob to win the first word, got %v %q", res, msg)
+	}
+	if ms := ge.History[0].ResponseMs; ms < 1500 || ms > 5000 {
+		t.Errorf("expected the response time to be recorded, got %dms", ms)
+	}
+
+	// carol answered the word bob already beat her to.
+	if res, msg := ge.SubmitAnswer("ねずみ", "carol", 0, false); res != ValidateRejected || msg != "ほかのプレイヤーが先に答えました" {
+		t.Errorf("expected a stale answer to be turned away, got %v %q", res, msg)
+	}
+	if ge.Players["carol"].Lives != defaultMaxLives {
+		t.Errorf("expected no penalty for a stale answer, got %d lives", ge.Players["carol"].Lives)
+	}
+
+	// A broken chain costs a life instead of a retry.
+	if res, _ := ge.SubmitAnswer("りんご", "carol", 1, false); res != ValidatePenalty {
+		t.Errorf("expected a wrong answer to cost a life, got %v", res)
+	}
+	if ge.Players["carol"].Lives != defaultMaxLives-1 {
+		t.Errorf("expected carol to lose a life, got %d", ge.Players["carol"].Lives)
+	}
+	if res, _ := ge.SubmitAnswer("こま", "bob", -1, false); res != ValidateOK {
+		t.Errorf("expected bob to answer the current word again, got %v", res)
+	}
+
+	if got := topScorer(map[string]int{"alice": 1, "bob": 3, "carol": 2}); got != "bob" {
+		t.Errorf("expected bob to top the scores, got %q", got)
+	}
+	if got := topScorer(map[string]int{"alice": 2, "bob": 2}); got != "" {
+		t.Errorf("expected a tie to have no winner, got %q", got)
+	}
+}
+
+func TestRaceReportedToClients(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_race.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("rc01", RoomSettings{Name: "race", Race: true})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	bob := &Player{Name: "bob", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	room.AddPlayer(bob)
+	server.handleStartGame(room)
+	server.submitAnswer(room, "bob", "ねこ", 0)
+
+	var accepted map[string]any
+	for len(alice.Send) > 0 {
+		var msg map[string]any
+		json.Unmarshal(<-alice.Send, &msg)
+		if msg["type"] == "word_accepted" {
+			accepted = msg
+		}
+	}
+	if accepted == nil || accepted["player"] != "bob" {
+		t.Fatalf("expected bob's answer to be accepted, got %v", accepted)
+	}
+	if _, ok := accepted["responseMs"].(float64); !ok {
+		t.Errorf("expected word_accepted to carry the response time, got %v", accepted)
+	}
+}
@@ -17,6 +17,7 @@ type RateLimitConfig struct {
 var defaultRateLimits = map[string]RateLimitConfig{
 	// Game actions: relatively strict
 	"answer":             {Rate: 1, Burst: 3},
+	"use_item":           {Rate: 1, Burst: 3},
 	"vote":               {Rate: 1, Burst: 3},
 	"challenge":          {Rate: 0.5, Burst: 2},
 	"rebuttal":           {Rate: 0.5, Burst: 2},
@@ -24,9 +25,11 @@ var defaultRateLimits = map[string]RateLimitConfig{
 
 	// Room management: moderate
 	"create_room": {Rate: 0.5, Burst: 2},
+	"start_daily": {Rate: 0.5, Burst: 2},
 	"join":        {Rate: 0.5, Burst: 3},
 	"leave_room":  {Rate: 1, Burst: 3},
 	"start_game":  {Rate: 0.5, Burst: 2},
+	"rematch":     {Rate: 0.5, Burst: 2},
 
 	// Read-only / lightweight: generous
 	"get_rooms":  {Rate: 2, Burst: 5},
@@ -55,18 +58,21 @@ func newTokenBucket(rate float64, burst int) *tokenBucket {
 	}
 }
 
-// allow checks if a token is available and consumes one if so.
-func (tb *tokenBucket) allow() bool {
+// refill adds tokens based on the time elapsed since the last check.
+func (tb *tokenBucket) refill() {
 	now := time.Now()
 	elapsed := now.Sub(tb.lastCheck).Seconds()
 	tb.lastCheck = now
 
-	// Add tokens based on elapsed time
 	tb.tokens += elapsed * tb.rate
 	if tb.tokens > tb.max {
 		tb.tokens = tb.max
 	}
+}
 
+// allow checks if a token is available and consumes one if so.
+func (tb *tokenBucket) allow() bool {
+	tb.refill()
 	if tb.tokens >= 1 {
 		tb.tokens--
 		return true
@@ -127,3 +133,62 @@ func (rl *ConnectionRateLimiter) Allow(msgType string) (bool, bool) {
 	}
 	return true, false
 }
+
+// FailureLimiter throttles repeated failures (e.g. wrong room passwords) per key.
+// Each key has a token bucket that only failures consume, so a key is blocked
+// once it has failed more than Burst times faster than Rate allows.
+type FailureLimiter struct {
+	mu      sync.Mutex
+	config  RateLimitConfig
+	buckets map[string]*tokenBucket
+}
+
+// NewFailureLimiter creates a FailureLimiter with the given budget per key.
+func NewFailureLimiter(config RateLimitConfig) *FailureLimiter {
+	return &FailureLimiter{
+		config:  config,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Blocked reports whether any of the keys has exhausted its failure budget.
+func (fl *FailureLimiter) Blocked(keys ...string) bool {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	for _, key := range keys {
+		bucket, ok := fl.buckets[key]
+		if !ok {
+			continue
+		}
+		bucket.refill()
+		if bucket.tokens < 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordFailure consumes one failure token for each key.
+func (fl *FailureLimiter) RecordFailure(keys ...string) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	for _, key := range keys {
+		bucket, ok := fl.buckets[key]
+		if !ok {
+			bucket = newTokenBucket(fl.config.Rate, fl.config.Burst)
+			fl.buckets[key] = bucket
+		}
+		bucket.allow()
+	}
+	fl.pruneLocked()
+}
+
+// pruneLocked drops buckets that have refilled completely. Caller must hold fl.mu.
+func (fl *FailureLimiter) pruneLocked() {
+	for key, bucket := range fl.buckets {
+		bucket.refill()
+		if bucket.tokens >= bucket.max {
+			delete(fl.buckets, key)
+		}
+	}
+}
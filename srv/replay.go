@@ -0,0 +1,79 @@
+package srv
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+)
+
+// replayData is the payload a replay is played back from.
+type replayData struct {
+	*GameResult
+	// Synthesized is true when the result predates event logs and only
+	// accepted words can be replayed.
+	Synthesized bool `json:"synthesized"`
+}
+
+// loadReplay loads a result the viewer may see and makes sure it has an
+// event log to play.
+func (s *Server) loadReplay(r *http.Request, id string) (*replayData, error) {
+	res, err := s.loadVisibleResult(r, id)
+	if err != nil {
+		return nil, err
+	}
+	data := &replayData{GameResult: res}
+	if len(res.Events) == 0 {
+		res.Events = eventsFromHistory(res.History)
+		data.Synthesized = true
+	}
+	return data, nil
+}
+
+// HandleReplayData returns a saved game with its full event log.
+func (s *Server) HandleReplayData(w http.ResponseWriter, r *http.Request) {
+	data, err := s.loadReplay(r, r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+// HandleReplayPage serves a page that plays a saved game back.
+func (s *Server) HandleReplayPage(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	data, err := s.loadReplay(r, id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	replayJSON, _ := json.Marshal(data)
+
+	tmpl, err := template.ParseFS(templatesFS, "templates/replay.html")
+	if err != nil {
+		slog.Error("parse replay template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page := struct {
+		Title      string
+		ResultURL  string
+		ReplayJSON template.JS
+		NoIndex    bool
+	}{
+		Title:      fmt.Sprintf("しりとりリプレイ - %s", data.RoomName),
+		ResultURL:  "/results/" + id,
+		ReplayJSON: template.JS(replayJSON),
+		NoIndex:    data.Visibility != VisibilityPublic,
+	}
+	if data.Visibility == VisibilityPrivate {
+		page.ResultURL += "?token=" + r.URL.Query().Get("token")
+	}
+	if err := tmpl.Execute(w, page); err != nil {
+		slog.Error("execute replay template", "error", err)
+	}
+}
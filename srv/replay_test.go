@@ -0,0 +1,110 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEngineRecordsEvents(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_replay.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("rp01", RoomSettings{Name: "replay", MinLen: 1, MaxLives: 1})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	bob := &Player{Name: "bob", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	room.AddPlayer(bob)
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+
+	server.handleAnswer(room, "alice", "しりとり")
+	server.handleChallenge(room, "bob")
+	resolved, result := room.ForceResolveVote()
+	if !resolved {
+		t.Fatal("expected the challenge vote to resolve")
+	}
+	server.broadcastVoteResult(room, result)
+
+	var types []string
+	for _, ev := range room.Engine.EventLog() {
+		types = append(types, ev.Type)
+	}
+	want := []string{EventStart, EventWord, EventChallenge, EventVoteResult, EventRevert, EventPenalty, EventEnd}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected events %v, got %v", want, types)
+	}
+
+	// The game_over callback saved the log with the result.
+	var resultID string
+	for len(alice.Send) > 0 {
+		var msg map[string]any
+		json.Unmarshal(<-alice.Send, &msg)
+		if msg["type"] == "game_over" {
+			resultID, _ = msg["resultId"].(string)
+		}
+	}
+	if resultID == "" {
+		t.Fatal("expected game_over with a resultId")
+	}
+
+	rec := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/results/{id}/replay", server.HandleReplayData)
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/results/"+resultID+"/replay", nil))
+	var data struct {
+		Events      []GameEvent `json:"events"`
+		Synthesized bool        `json:"synthesized"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&data); err != nil {
+		t.Fatalf("decode replay: %v", err)
+	}
+	if data.Synthesized || len(data.Events) != len(want) {
+		t.Fatalf("expected %d recorded events, got %d (synthesized=%v)", len(want), len(data.Events), data.Synthesized)
+	}
+	last := data.Events[len(data.Events)-1]
+	if last.Type != EventEnd || last.Player != "bob" || last.Lives["alice"] != 0 {
+		t.Errorf("unexpected end event: %+v", last)
+	}
+}
+
+func TestReplayFallsBackToHistory(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_replay.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	now := time.Now().UTC()
+	id, err := server.saveGameResult(&GameResult{
+		RoomName: "old",
+		History: []WordEntry{
+			{Word: "しりとり", Player: "alice", Time: now.Format(time.RFC3339)},
+			{Word: "りす", Player: "bob", Time: now.Add(3 * time.Second).Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+	data, err := server.loadReplay(httptest.NewRequest("GET", "/", nil), id)
+	if err != nil {
+		t.Fatalf("load replay: %v", err)
+	}
+	if !data.Synthesized || len(data.Events) != 2 || data.Events[1].Word != "りす" {
+		t.Errorf("expected two synthesized word events, got %+v", data.Events)
+	}
+
+	rec := httptest.NewRecorder()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /results/{id}/replay", server.HandleReplayPage)
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/results/"+id+"/replay", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "りす") {
+		t.Errorf("expected replay page with events, got %d", rec.Code)
+	}
+}
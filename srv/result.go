@@ -21,8 +21,12 @@ type GameResult struct {
 	Reason      string         `json:"reason"`
 	Scores      map[string]int `json:"scores"`
 	History     []WordEntry    `json:"history"`
+	Events      []GameEvent    `json:"events,omitempty"`
 	Lives       map[string]int `json:"lives"`
 	PlayerCount int            `json:"playerCount"`
+	MatchID     string         `json:"matchId,omitempty"`
+	Round       int            `json:"round,omitempty"`
+	Visibility  string         `json:"visibility"`
 	CreatedAt   time.Time      `json:"createdAt"`
 }
 
@@ -32,92 +36,101 @@ func generateResultID() string {
 	return hex.EncodeToString(b)
 }
 
-// makeGameOverCallback returns a callback that saves the game result to DB
-// and adds the resultId to the game_over message.
+// makeGameOverCallback returns a callback that saves the game result to DB,
+// records the round on the room's match and adds the resultId and match
+// summary to the game_over message. The room owner is sent the token that
+// controls the result's visibility. Results are only ever written here, never
+// from client submissions.
 func (s *Server) makeGameOverCallback() func(room *Room, msg map[string]any) map[string]any {
 	return func(room *Room, msg map[string]any) map[string]any {
-		roomName := room.Settings.Name
-		genre := room.Settings.Genre
 		winner, _ := msg["winner"].(string)
+		loser, _ := msg["loser"].(string)
 		reason, _ := msg["reason"].(string)
 
-		var scores map[string]int
+		res := &GameResult{
+			RoomName:   room.Settings.Name,
+			Genre:      genreLabel(room.Settings),
+			Winner:     winner,
+			Reason:     reason,
+			Visibility: defaultVisibility,
+		}
 		if s, ok := msg["scores"].(map[string]int); ok {
-			scores = s
+			res.Scores = s
 		}
-		var history []WordEntry
 		if h, ok := msg["history"].([]WordEntry); ok {
-			history = h
+			res.History = h
 		}
-		var lives map[string]int
 		if l, ok := msg["lives"].(map[string]int); ok {
-			lives = l
+			res.Lives = l
+		}
+		if !room.StartedAt.IsZero() {
+			metrics.gameDuration.ObserveSince(room.StartedAt)
+		}
+		if room.Engine != nil {
+			room.Engine.RecordEvent(GameEvent{Type: EventEnd, Player: winner, Reason: reason})
+			res.Events = room.Engine.EventLog()
+		}
+		if room.Match != nil {
+			res.MatchID = room.Match.ID
+			res.Round = room.Match.NextRound()
 		}
 
-		id, err := s.saveGameResult(roomName, genre, winner, reason, scores, history, lives)
+		id, err := s.saveGameResult(res)
 		if err != nil {
 			slog.Error("save game result on game_over", "error", err)
 		} else {
 			msg["resultId"] = id
+			s.sendResultToken(room, id, res.Visibility)
+		}
+		if summary := s.recordMatchRound(room, res.RoomName, winner, loser, id, res.Scores); summary != nil {
+			msg["match"] = summary
 		}
 		return msg
 	}
 }
 
-// saveGameResult saves a game result to the DB and returns the result ID.
-// Called server-side when a game ends, so only one save per game.
-func (s *Server) saveGameResult(roomName, genre, winner, reason string, scores map[string]int, history []WordEntry, lives map[string]int) (string, error) {
+// saveGameResult saves a game result, its event log and its search index
+// entry to the DB and returns the result ID. Called server-side when a game
+// ends, so only one save per game.
+func (s *Server) saveGameResult(res *GameResult) (string, error) {
+	defer metrics.dbWriteLatency.ObserveSince(time.Now(), "save_game_result")
 	id := generateResultID()
-	scoresJSON, _ := json.Marshal(scores)
-	historyJSON, _ := json.Marshal(history)
-	livesJSON, _ := json.Marshal(lives)
-	playerCount := len(scores)
+	scoresJSON, _ := json.Marshal(res.Scores)
+	historyJSON, _ := json.Marshal(res.History)
+	livesJSON, _ := json.Marshal(res.Lives)
+	playerCount := len(res.Scores)
 	if playerCount == 0 {
 		playerCount = 1
 	}
-	_, err := s.DB.Exec(
-		`INSERT INTO game_results (id, room_name, genre, winner, reason, scores_json, history_json, lives_json, player_count, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		id, roomName, genre, winner, reason,
+	if !validVisibility(res.Visibility) {
+		res.Visibility = defaultVisibility
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(
+		`INSERT INTO game_results (id, room_name, genre, winner, reason, scores_json, history_json, lives_json, player_count, match_id, round, visibility, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, res.RoomName, res.Genre, res.Winner, res.Reason,
 		string(scoresJSON), string(historyJSON), string(livesJSON),
-		playerCount, time.Now().UTC(),
+		playerCount, res.MatchID, res.Round, res.Visibility, time.Now().UTC(),
 	)
 	if err != nil {
 		return "", err
 	}
-	return id, nil
-}
-
-// HandleSaveResult saves a game result and returns the ID.
-func (s *Server) HandleSaveResult(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	var req struct {
-		RoomName string         `json:"roomName"`
-		Genre    string         `json:"genre"`
-		Winner   string         `json:"winner"`
-		Reason   string         `json:"reason"`
-		Scores   map[string]int `json:"scores"`
-		History  []WordEntry    `json:"history"`
-		Lives    map[string]int `json:"lives"`
+	if err := insertGameEvents(tx, id, res.Events); err != nil {
+		return "", err
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
-		return
+	if err := indexResultWords(tx, id, res.History); err != nil {
+		return "", err
 	}
-
-	id, err := s.saveGameResult(req.RoomName, req.Genre, req.Winner, req.Reason, req.Scores, req.History, req.Lives)
-	if err != nil {
-		slog.Error("save result", "error", err)
-		http.Error(w, "internal error", http.StatusInternalServerError)
-		return
+	if err := tx.Commit(); err != nil {
+		return "", err
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"id": id})
+	return id, nil
 }
 
 // loadResult loads a game result from the database.
@@ -129,16 +142,19 @@ func (s *Server) loadResult(id string) (*GameResult, error) {
 		livesStr  string
 	)
 	err := s.DB.QueryRow(
-		`SELECT id, room_name, genre, winner, reason, scores_json, history_json, lives_json, player_count, created_at
+		`SELECT id, room_name, genre, winner, reason, scores_json, history_json, lives_json, player_count, match_id, round, visibility, created_at
 		 FROM game_results WHERE id = ?`, id,
 	).Scan(&result.ID, &result.RoomName, &result.Genre, &result.Winner, &result.Reason,
-		&scoresStr, &histStr, &livesStr, &result.PlayerCount, &result.CreatedAt)
+		&scoresStr, &histStr, &livesStr, &result.PlayerCount, &result.MatchID, &result.Round, &result.Visibility, &result.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 	json.Unmarshal([]byte(scoresStr), &result.Scores)
 	json.Unmarshal([]byte(histStr), &result.History)
 	json.Unmarshal([]byte(livesStr), &result.Lives)
+	if result.Events, err = s.loadGameEvents(id); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
@@ -149,6 +165,10 @@ type resultPageData struct {
 	OGPURL      string
 	PageURL     string
 	ResultJSON  template.JS
+	// NoIndex keeps unlisted and private results out of search engines.
+	NoIndex bool
+	// TokenQuery carries the owner token to linked pages of a private result.
+	TokenQuery string
 }
 
 // HandleViewResultPage serves the result page with OGP meta tags.
@@ -158,7 +178,7 @@ func (s *Server) HandleViewResultPage(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
-	result, err := s.loadResult(id)
+	result, err := s.loadVisibleResult(r, id)
 	if err != nil {
 		http.NotFound(w, r)
 		return
@@ -188,7 +208,7 @@ func (s *Server) HandleViewResultPage(w http.ResponseWriter, r *http.Request) {
 		scheme = fwd
 	}
 	baseURL := fmt.Sprintf("%s://%s", scheme, r.Host)
-	ogpURL := fmt.Sprintf("%s/results/%s/ogp.svg", baseURL, id)
+	ogpURL := fmt.Sprintf("%s/results/%s/ogp.png", baseURL, id)
 	pageURL := fmt.Sprintf("%s/results/%s", baseURL, id)
 
 	resultJSON, _ := json.Marshal(result)
@@ -207,6 +227,10 @@ func (s *Server) HandleViewResultPage(w http.ResponseWriter, r *http.Request) {
 		OGPURL:      ogpURL,
 		PageURL:     pageURL,
 		ResultJSON:  template.JS(resultJSON),
+		NoIndex:     result.Visibility != VisibilityPublic,
+	}
+	if result.Visibility == VisibilityPrivate {
+		data.TokenQuery = "?token=" + r.URL.Query().Get("token")
 	}
 	if err := tmpl.Execute(w, data); err != nil {
 		slog.Error("execute result template", "error", err)
@@ -0,0 +1,322 @@
+package srv
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Rule outcomes reported to clients with each rule.
+const (
+	OutcomeReject  = "reject"  // the word is refused; the player tries again
+	OutcomePenalty = "penalty" // the word is refused and the player loses a life
+	OutcomeVote    = "vote"    // the other players vote on whether to accept it
+)
+
+// WordCheck is what a Rule sees of a submitted word.
+type WordCheck struct {
+	Word     string // normalized word as typed
+	Hiragana string // Word folded to hiragana
+	Previous string // hiragana of the word being continued; "" for the first word
+	Player   string
+	Turn     int             // number of the word being played, from 1
+	MinLen   int             // ladder mode: length this word must reach
+	Forced   string          // kana a force item requires the word to start with
+	Used     map[string]bool // hiragana of words already played; read only
+	Settings RoomSettings
+}
+
+// RuleInfo describes an active rule for clients.
+type RuleInfo struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Outcome     string `json:"outcome"`
+}
+
+// Rule is one check in the word validation pipeline. Check returns
+// ValidateOK to let the word through, or the result the word gets
+// (ValidateRejected, ValidatePenalty or ValidateVote) and the reason shown
+// to players. Rules run in order and the first one that doesn't pass decides.
+type Rule interface {
+	Info() RuleInfo
+	Check(w WordCheck) (ValidateResult, string)
+}
+
+// RuleFactory returns the rule the settings call for, or nil if they don't
+// enable it.
+type RuleFactory func(RoomSettings) Rule
+
+var (
+	ruleMu        sync.RWMutex
+	ruleFactories = []RuleFactory{
+		newLengthRule,
+		newLadderRule,
+		newChainRule,
+		newReuseRule,
+		newNEndingRule,
+		newNoDakutenRule,
+		newAllowedRowsRule,
+	}
+)
+
+// RegisterRule adds a rule to the pipeline of every game started afterwards.
+// Registered rules run after the built-in ones, in registration order.
+func RegisterRule(f RuleFactory) {
+	ruleMu.Lock()
+	defer ruleMu.Unlock()
+	ruleFactories = append(ruleFactories, f)
+}
+
+// RulesFor composes the rule pipeline for a room's settings. The room's
+// custom rules run last. Custom rules are compiled here, so a game engine
+// composes its pipeline once and keeps it.
+func RulesFor(s RoomSettings) []Rule {
+	return append(registeredRules(s), customRules(s)...)
+}
+
+func registeredRules(s RoomSettings) []Rule {
+	ruleMu.RLock()
+	defer ruleMu.RUnlock()
+	var rules []Rule
+	for _, f := range ruleFactories {
+		if rule := f(s); rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// ruleInfos describes the rules a room's settings enable. It doesn't compile
+// custom rules, since it runs every time a room's state is sent.
+func ruleInfos(s RoomSettings) []RuleInfo {
+	return append(describeRules(registeredRules(s)), customRuleInfos(s)...)
+}
+
+func describeRules(rules []Rule) []RuleInfo {
+	infos := make([]RuleInfo, len(rules))
+	for i, rule := range rules {
+		infos[i] = rule.Info()
+	}
+	return infos
+}
+
+// lengthRule bounds word length in the room's length unit.
+type lengthRule struct {
+	min, max int
+	unit     string
+}
+
+func newLengthRule(s RoomSettings) Rule {
+	if s.MinLen <= 0 && s.MaxLen <= 0 {
+		return nil
+	}
+	return lengthRule{min: s.MinLen, max: s.MaxLen, unit: s.LengthUnit}
+}
+
+func (r lengthRule) Info() RuleInfo {
+	name := lengthUnitName(r.unit)
+	var desc string
+	switch {
+	case r.min > 0 && r.max > 0:
+		desc = fmt.Sprintf("%d〜%d%s", r.min, r.max, name)
+	case r.min > 0:
+		desc = fmt.Sprintf("%d%s以上", r.min, name)
+	default:
+		desc = fmt.Sprintf("%d%s以下", r.max, name)
+	}
+	return RuleInfo{ID: "length", Description: desc, Outcome: OutcomeReject}
+}
+
+func (r lengthRule) Check(w WordCheck) (ValidateResult, string) {
+	n := wordLength(w.Hiragana, r.unit)
+	if r.min > 0 && n < r.min {
+		return ValidateRejected, fmt.Sprintf("%d%s以上で入力してください", r.min, lengthUnitName(r.unit))
+	}
+	if r.max > 0 && n > r.max {
+		return ValidateRejected, fmt.Sprintf("%d%s以下で入力してください", r.max, lengthUnitName(r.unit))
+	}
+	return ValidateOK, ""
+}
+
+// ladderRule requires each word to be longer than the one before it. The
+// engine tracks the current minimum and passes it in WordCheck.MinLen.
+type ladderRule struct{ unit string }
+
+func newLadderRule(s RoomSettings) Rule {
+	if !s.Ladder {
+		return nil
+	}
+	return ladderRule{unit: s.LengthUnit}
+}
+
+func (r ladderRule) Info() RuleInfo {
+	return RuleInfo{ID: "ladder", Description: fmt.Sprintf("前の言葉より1%s以上長く（ミスでリセット）", lengthUnitName(r.unit)), Outcome: OutcomeReject}
+}
+
+func (r ladderRule) Check(w WordCheck) (ValidateResult, string) {
+	if wordLength(w.Hiragana, r.unit) < w.MinLen {
+		return ValidateRejected, fmt.Sprintf("%d%s以上で入力してください（はしごルール）", w.MinLen, lengthUnitName(r.unit))
+	}
+	return ValidateOK, ""
+}
+
+// Chain modes for RoomSettings.ChainMode: how each word must link to the
+// previous one.
+const (
+	ChainNormal  = ""        // start with the previous word's last kana
+	ChainReverse = "reverse" // end with the previous word's first kana (頭取り)
+	ChainTwoMora = "two"     // start with the previous word's last two morae
+	ChainDouble  = "double"  // either normal or reverse
+)
+
+// chainMoraCount is how many morae link words in ChainTwoMora.
+const chainMoraCount = 2
+
+// linkKana returns the kana a word passes on to the next one under mode:
+// the kana the next word must start with, or in ChainReverse end with.
+func linkKana(hiragana, mode string) rune {
+	if mode == ChainReverse {
+		return getFirstChar(hiragana)
+	}
+	return getLastChar(hiragana)
+}
+
+// chainRule requires each word to link to the previous one as the room's
+// chain mode says.
+type chainRule struct{ mode string }
+
+func newChainRule(s RoomSettings) Rule { return chainRule{mode: s.ChainMode} }
+
+func (r chainRule) Info() RuleInfo {
+	desc := "前の言葉の最後の文字から始める"
+	switch r.mode {
+	case ChainReverse:
+		desc = "前の言葉の最初の文字で終わる（逆しりとり）"
+	case ChainTwoMora:
+		desc = "前の言葉の最後の2音から始める"
+	case ChainDouble:
+		desc = "前の言葉の最後の文字から始めるか、最初の文字で終わる"
+	}
+	return RuleInfo{ID: "chain", Description: desc, Outcome: OutcomeReject}
+}
+
+func (r chainRule) Check(w WordCheck) (ValidateResult, string) {
+	// A force item overrides the link for one word.
+	if w.Forced != "" {
+		if string(getFirstChar(w.Hiragana)) != w.Forced {
+			return ValidateRejected, fmt.Sprintf("「%s」から始まる言葉を入力してください（頭文字指定）", w.Forced)
+		}
+		return ValidateOK, ""
+	}
+	if w.Previous == "" {
+		return ValidateOK, ""
+	}
+	last, first := getLastChar(w.Previous), getFirstChar(w.Previous)
+	follows := getFirstChar(w.Hiragana) == last
+	precedes := getLastChar(w.Hiragana) == first
+	switch r.mode {
+	case ChainReverse:
+		if !precedes {
+			return ValidateRejected, fmt.Sprintf("「%c」で終わる言葉を入力してください", first)
+		}
+	case ChainTwoMora:
+		// A one-mora previous word (e.g. an opening kana) links by that mora.
+		prev := linkMora(w.Previous)
+		n := min(len(prev), chainMoraCount)
+		link := strings.Join(prev[len(prev)-n:], "")
+		next := linkMora(w.Hiragana)
+		if len(next) < n || strings.Join(next[:n], "") != link {
+			return ValidateRejected, fmt.Sprintf("「%s」から始まる言葉を入力してください", link)
+		}
+	case ChainDouble:
+		if !follows && !precedes {
+			return ValidateRejected, fmt.Sprintf("「%c」から始まるか「%c」で終わる言葉を入力してください", last, first)
+		}
+	default:
+		if !follows {
+			return ValidateRejected, fmt.Sprintf("「%c」から始まる言葉を入力してください", last)
+		}
+	}
+	return ValidateOK, ""
+}
+
+// reuseRule forbids playing a word twice.
+type reuseRule struct{}
+
+func newReuseRule(RoomSettings) Rule { return reuseRule{} }
+
+func (reuseRule) Info() RuleInfo {
+	return RuleInfo{ID: "reuse", Description: "同じ言葉は使えない", Outcome: OutcomePenalty}
+}
+
+func (reuseRule) Check(w WordCheck) (ValidateResult, string) {
+	if w.Used[w.Hiragana] {
+		return ValidatePenalty, "この言葉はすでに使われています"
+	}
+	return ValidateOK, ""
+}
+
+// nEndingRule forbids words ending in ん. Reverse shiritori links through
+// the first kana instead, so a final ん doesn't end the chain there.
+type nEndingRule struct{}
+
+func newNEndingRule(s RoomSettings) Rule {
+	if s.ChainMode == ChainReverse {
+		return nil
+	}
+	return nEndingRule{}
+}
+
+func (nEndingRule) Info() RuleInfo {
+	return RuleInfo{ID: "n-ending", Description: "「ん」で終わる言葉は使えない", Outcome: OutcomePenalty}
+}
+
+func (nEndingRule) Check(w WordCheck) (ValidateResult, string) {
+	runes := []rune(w.Hiragana)
+	if len(runes) > 0 && runes[len(runes)-1] == 'ん' {
+		return ValidatePenalty, "「ん」で終わる言葉を使いました"
+	}
+	return ValidateOK, ""
+}
+
+// noDakutenRule forbids voiced and semi-voiced kana.
+type noDakutenRule struct{}
+
+func newNoDakutenRule(s RoomSettings) Rule {
+	if !s.NoDakuten {
+		return nil
+	}
+	return noDakutenRule{}
+}
+
+func (noDakutenRule) Info() RuleInfo {
+	return RuleInfo{ID: "no-dakuten", Description: "濁音・半濁音禁止", Outcome: OutcomePenalty}
+}
+
+func (noDakutenRule) Check(w WordCheck) (ValidateResult, string) {
+	if badChar := ValidateNoDakuten(w.Hiragana); badChar != 0 {
+		return ValidatePenalty, fmt.Sprintf("「%c」は濁音・半濁音の文字です（濁音・半濁音禁止ルール）", badChar)
+	}
+	return ValidateOK, ""
+}
+
+// allowedRowsRule limits words to kana from the allowed rows.
+type allowedRowsRule struct{ rows []string }
+
+func newAllowedRowsRule(s RoomSettings) Rule {
+	if len(s.AllowedRows) == 0 {
+		return nil
+	}
+	return allowedRowsRule{rows: s.AllowedRows}
+}
+
+func (r allowedRowsRule) Info() RuleInfo {
+	return RuleInfo{ID: "allowed-rows", Description: "使用可能な行: " + formatAllowedRows(r.rows), Outcome: OutcomePenalty}
+}
+
+func (r allowedRowsRule) Check(w WordCheck) (ValidateResult, string) {
+	if badChar, badRow := ValidateAllowedRows(w.Hiragana, r.rows); badChar != 0 {
+		return ValidatePenalty, fmt.Sprintf("「%c」は%sの文字です（使用可能な行: %s）", badChar, badRow, formatAllowedRows(r.rows))
+	}
+	return ValidateOK, ""
+}
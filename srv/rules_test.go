@@ -0,0 +1,225 @@
+package srv
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRulesFor(t *testing.T) {
+	ids := func(s RoomSettings) []string {
+		var out []string
+		for _, info := range ruleInfos(s) {
+			out = append(out, info.ID)
+		}
+		return out
+	}
+	if got := ids(RoomSettings{}); !slices.Equal(got, []string{"chain", "reuse", "n-ending"}) {
+		t.Errorf("unexpected default rules %v", got)
+	}
+	all := RoomSettings{MinLen: 2, NoDakuten: true, AllowedRows: []string{"あ行"}}
+	if got := ids(all); !slices.Equal(got, []string{"length", "chain", "reuse", "n-ending", "no-dakuten", "allowed-rows"}) {
+		t.Errorf("unexpected rules %v", got)
+	}
+	if info := ruleInfos(RoomSettings{MinLen: 2, MaxLen: 4, LengthUnit: LengthMora})[0]; info.Description != "2〜4音" || info.Outcome != OutcomeReject {
+		t.Errorf("unexpected length rule %+v", info)
+	}
+}
+
+// fruitRule sends words that aren't fruit to a vote.
+type fruitRule struct{}
+
+func (fruitRule) Info() RuleInfo {
+	return RuleInfo{ID: "fruit", Description: "くだものだけ", Outcome: OutcomeVote}
+}
+
+func (fruitRule) Check(w WordCheck) (ValidateResult, string) {
+	if !slices.Contains([]string{"りんご", "ごれんし", "しーくわーさー"}, w.Hiragana) {
+		return ValidateVote, "「" + w.Word + "」はくだものではないかもしれません"
+	}
+	return ValidateOK, ""
+}
+
+func TestRegisteredRuleVote(t *testing.T) {
+	saved := ruleFactories
+	t.Cleanup(func() { ruleFactories = saved })
+	RegisterRule(func(s RoomSettings) Rule {
+		if s.Genre != "くだもの" {
+			return nil
+		}
+		return fruitRule{}
+	})
+
+	server, err := New(filepath.Join(t.TempDir(), "test_rules.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("ru01", RoomSettings{Name: "fruit", Genre: "くだもの"})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	bob := &Player{Name: "bob", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	room.AddPlayer(bob)
+	server.handleStartGame(room)
+
+	var started map[string]any
+	json.Unmarshal(<-alice.Send, &started)
+	rules, _ := started["rules"].([]any)
+	if len(rules) != 4 || rules[3].(map[string]any)["outcome"] != OutcomeVote {
+		t.Fatalf("expected game_started to list the registered rule, got %v", started["rules"])
+	}
+
+	if res, _ := room.ValidateAndSubmitWord("りんご", "alice"); res != ValidateOK {
+		t.Fatalf("expected りんご to pass, got %v", res)
+	}
+	// Built-in rules run first: a broken chain is rejected, not voted on.
+	if res, _ := room.ValidateAndSubmitWord("さる", "bob"); res != ValidateRejected {
+		t.Errorf("expected the chain rule to reject さる, got %v", res)
+	}
+	res, reason := room.ValidateAndSubmitWord("ゴリラ", "bob")
+	if res != ValidateVote || reason != "「ゴリラ」はくだものではないかもしれません" {
+		t.Fatalf("expected ゴリラ to go to a vote, got %v %q", res, reason)
+	}
+	pv := room.Votes.GetPending()
+	if pv == nil || pv.Word != "ゴリラ" || pv.Hiragana != "ごりら" || !pv.Votes["bob"] {
+		t.Fatalf("expected a word vote with bob's accept, got %+v", pv)
+	}
+	if resolved, result := room.CastVote("alice", true); !resolved || !result.Accepted {
+		t.Fatalf("expected the vote to accept ゴリラ, got %+v", result)
+	}
+	if _, current, _, _ := room.Engine.Snapshot(); current != "ゴリラ" {
+		t.Errorf("expected the accepted word to be played, got %q", current)
+	}
+
+	state := room.GetState()
+	if infos, _ := state["rules"].([]RuleInfo); len(infos) != 4 {
+		t.Errorf("expected room state to report the rules, got %v", state["rules"])
+	}
+}
+
+func TestChainModes(t *testing.T) {
+	for _, tc := range []struct {
+		mode, previous, word string
+		want                 ValidateResult
+	}{
+		{ChainNormal, "しりとり", "りんご", ValidateOK},
+		{ChainNormal, "こーひー", "ひよこ", ValidateOK},
+		{ChainNormal, "しりとり", "ごりら", ValidateRejected},
+		{ChainReverse, "しりとり", "むし", ValidateOK},
+		{ChainReverse, "しりとり", "りす", ValidateRejected},
+		{ChainReverse, "しゃしん", "いしゃ", ValidateRejected},
+		{ChainReverse, "しゃしん", "ばーべきゅー", ValidateRejected},
+		{ChainReverse, "ゆうき", "いしゅ", ValidateOK},
+		{ChainReverse, "ゆうき", "じゅー", ValidateOK},
+		{ChainTwoMora, "しりとり", "とりかご", ValidateOK},
+		{ChainTwoMora, "しりとり", "りんご", ValidateRejected},
+		{ChainTwoMora, "きしゃ", "しゃしん", ValidateRejected},
+		{ChainTwoMora, "きしゃ", "きしゃどう", ValidateOK},
+		{ChainTwoMora, "こーひー", "こひつじ", ValidateOK},
+		{ChainTwoMora, "らっぱ", "らぱん", ValidateOK},
+		{ChainTwoMora, "か", "かめ", ValidateOK},
+		{ChainDouble, "しりとり", "りんご", ValidateOK},
+		{ChainDouble, "しりとり", "むし", ValidateOK},
+		{ChainDouble, "しりとり", "ごりら", ValidateRejected},
+	} {
+		res, _ := chainRule{mode: tc.mode}.Check(WordCheck{Previous: tc.previous, Hiragana: tc.word})
+		if res != tc.want {
+			t.Errorf("%q: %s after %s: got %v, want %v", tc.mode, tc.word, tc.previous, res, tc.want)
+		}
+	}
+
+	if _, msg := (chainRule{mode: ChainTwoMora}).Check(WordCheck{Previous: "きしゃ", Hiragana: "しゃけ"}); msg != "「きしゃ」から始まる言葉を入力してください" {
+		t.Errorf("unexpected two-mora message %q", msg)
+	}
+
+	// Reverse shiritori links through the first kana, so ん endings are fine.
+	ge := NewGameEngine(RoomSettings{ChainMode: ChainReverse}, []string{"alice", "bob"}, nil)
+	ge.ValidateAndSubmitWord("しりとり", "alice", false)
+	if res, msg := ge.ValidateAndSubmitWord("ぱそこん", "bob", false); res != ValidateRejected {
+		t.Errorf("expected ぱそこん not to end in し, got %v %q", res, msg)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("みかんし", "bob", false); res != ValidateOK {
+		t.Errorf("expected みかんし to precede しりとり, got %v", res)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("ぱん", "alice", false); res != ValidateRejected {
+		t.Errorf("expected ぱん not to end in み, got %v", res)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("まみ", "alice", false); res != ValidateOK {
+		t.Errorf("expected まみ to precede みかんし, got %v", res)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("さんま", "bob", false); res != ValidateOK {
+		t.Errorf("expected さんま to precede まみ, got %v", res)
+	}
+}
+
+func TestLadderMode(t *testing.T) {
+	ge := NewGameEngine(RoomSettings{Ladder: true, MinLen: 2, MaxLen: 5}, []string{"alice", "bob"}, nil)
+	if ge.MinLength() != 2 {
+		t.Fatalf("expected the ladder to start at MinLen, got %d", ge.MinLength())
+	}
+	if res, _ := ge.ValidateAndSubmitWord("ねこ", "alice", false); res != ValidateOK {
+		t.Fatalf("expected ねこ to be accepted, got %v", res)
+	}
+	if res, msg := ge.ValidateAndSubmitWord("こま", "bob", false); res != ValidateRejected || msg != "3文字以上で入力してください（はしごルール）" {
+		t.Errorf("expected a word no longer than ねこ to be rejected, got %v %q", res, msg)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("こあら", "bob", false); res != ValidateOK {
+		t.Fatalf("expected こあら to climb the ladder, got %v", res)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("らいおん", "alice", false); res != ValidatePenalty {
+		t.Fatalf("expected らいおん to cost a life, got %v", res)
+	}
+	if ge.MinLength() != 2 {
+		t.Errorf("expected a penalty to reset the ladder, got %d", ge.MinLength())
+	}
+	if res, _ := ge.ValidateAndSubmitWord("らくだ", "alice", false); res != ValidateOK {
+		t.Fatalf("expected らくだ to be accepted, got %v", res)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("だいどころ", "bob", false); res != ValidateOK {
+		t.Fatalf("expected だいどころ to be accepted, got %v", res)
+	}
+	if ge.MinLength() != 2 {
+		t.Errorf("expected the ladder to restart past MaxLen, got %d", ge.MinLength())
+	}
+
+	// Mora mode measures in morae, and the opening word counts as a rung.
+	ge = NewGameEngine(RoomSettings{Ladder: true, LengthUnit: LengthMora, StartMode: StartFixed, StartWord: "しゃしょう"}, []string{"alice"}, nil)
+	ge.Open()
+	if ge.MinLength() != 4 {
+		t.Errorf("expected しゃしょう (3 morae) to set the ladder to 4, got %d", ge.MinLength())
+	}
+	if got := ge.ExportState().LadderMin; RestoreGameEngine(ge.Settings, ge.ExportState(), nil).MinLength() != got {
+		t.Errorf("expected the ladder to survive a snapshot")
+	}
+}
+
+func TestLadderReportedToClients(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_ladder.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("ld01", RoomSettings{Name: "ladder", Ladder: true})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	server.handleStartGame(room)
+	server.handleAnswer(room, "alice", "ねこ")
+
+	var accepted map[string]any
+	for len(alice.Send) > 0 {
+		var msg map[string]any
+		json.Unmarshal(<-alice.Send, &msg)
+		if msg["type"] == "word_accepted" {
+			accepted = msg
+		}
+	}
+	if accepted == nil || accepted["ladderMin"] != float64(3) {
+		t.Errorf("expected word_accepted to report the next minimum, got %v", accepted)
+	}
+	if got := room.GetState()["ladderMin"]; got != 3 {
+		t.Errorf("expected room_state to report the ladder, got %v", got)
+	}
+}
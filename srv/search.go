@@ -0,0 +1,292 @@
+package srv
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resultsPerPage is the page size of result listings.
+const resultsPerPage = 20
+
+// ResultSummary is a saved result as shown in listings.
+type ResultSummary struct {
+	ID          string    `json:"id"`
+	RoomName    string    `json:"roomName"`
+	Genre       string    `json:"genre"`
+	Winner      string    `json:"winner"`
+	Players     []string  `json:"players"`
+	ChainLength int       `json:"chainLength"`
+	Chain       string    `json:"chain"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// resultFilter narrows a result listing. Zero values don't filter.
+type resultFilter struct {
+	Query    string
+	Genre    string
+	Player   string
+	Winner   string
+	MinChain int
+	From, To time.Time
+	Page     int
+}
+
+// parseResultFilter reads filters from a query string. Dates are inclusive.
+func parseResultFilter(q url.Values) (resultFilter, error) {
+	f := resultFilter{
+		Query:  strings.TrimSpace(q.Get("q")),
+		Genre:  strings.TrimSpace(q.Get("genre")),
+		Player: strings.TrimSpace(q.Get("player")),
+		Winner: strings.TrimSpace(q.Get("winner")),
+		Page:   1,
+	}
+	if v := q.Get("minChain"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, fmt.Errorf("invalid minChain")
+		}
+		f.MinChain = n
+	}
+	if v := q.Get("from"); v != "" {
+		t, _, err := parseExportDate(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid from date")
+		}
+		f.From = t
+	}
+	if v := q.Get("to"); v != "" {
+		t, dateOnly, err := parseExportDate(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid to date")
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		f.To = t
+	}
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return f, fmt.Errorf("invalid page")
+		}
+		f.Page = n
+	}
+	return f, nil
+}
+
+// ftsPhrase quotes s as an FTS5 phrase.
+func ftsPhrase(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// likeEsc escapes LIKE wildcards; use with ESCAPE '\'.
+func likeEsc(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// wordSearchClause matches results whose words or readings contain q. The
+// trigram index needs at least three characters; shorter terms are scanned.
+func wordSearchClause(q string) (string, []any) {
+	reading := toHiragana(normalizeWord(q))
+	if len([]rune(q)) >= 3 {
+		match := ftsPhrase(q)
+		if reading != q {
+			match += " OR " + ftsPhrase(reading)
+		}
+		return `gr.id IN (SELECT result_id FROM game_results_fts WHERE game_results_fts MATCH ?)`, []any{match}
+	}
+	return `gr.id IN (SELECT result_id FROM game_results_fts WHERE words LIKE ? ESCAPE '\' OR readings LIKE ? ESCAPE '\')`,
+		[]any{"%" + likeEsc(q) + "%", "%" + likeEsc(reading) + "%"}
+}
+
+// searchResults lists public results matching f, newest first, and reports
+// whether there is another page.
+func (s *Server) searchResults(f resultFilter) ([]ResultSummary, bool, error) {
+	// Only public results are listed; unlisted ones are reachable by link only.
+	where := []string{"gr.visibility = ?"}
+	args := []any{VisibilityPublic}
+	if f.Query != "" {
+		clause, a := wordSearchClause(f.Query)
+		where = append(where, clause)
+		args = append(args, a...)
+	}
+	if f.Genre != "" {
+		where = append(where, "gr.genre = ?")
+		args = append(args, f.Genre)
+	}
+	if f.Winner != "" {
+		where = append(where, "gr.winner = ?")
+		args = append(args, f.Winner)
+	}
+	if f.Player != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM json_each(gr.scores_json) WHERE key = ?)")
+		args = append(args, f.Player)
+	}
+	if f.MinChain > 0 {
+		where = append(where, "json_array_length(gr.history_json) >= ?")
+		args = append(args, f.MinChain)
+	}
+	if !f.From.IsZero() {
+		where = append(where, "gr.created_at >= ?")
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		where = append(where, "gr.created_at < ?")
+		args = append(args, f.To.UTC())
+	}
+	page := max(f.Page, 1)
+	args = append(args, resultsPerPage+1, (page-1)*resultsPerPage)
+
+	rows, err := s.DB.Query(
+		`SELECT gr.id, gr.room_name, gr.genre, gr.winner, gr.scores_json, gr.history_json, gr.created_at
+		 FROM game_results gr WHERE `+strings.Join(where, " AND ")+`
+		 ORDER BY gr.created_at DESC, gr.id DESC LIMIT ? OFFSET ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+	var results []ResultSummary
+	for rows.Next() {
+		var (
+			sum                ResultSummary
+			scoresStr, histStr string
+		)
+		if err := rows.Scan(&sum.ID, &sum.RoomName, &sum.Genre, &sum.Winner, &scoresStr, &histStr, &sum.CreatedAt); err != nil {
+			return nil, false, err
+		}
+		var scores map[string]int
+		var history []WordEntry
+		json.Unmarshal([]byte(scoresStr), &scores)
+		json.Unmarshal([]byte(histStr), &history)
+		sum.Players = vizPlayers(&GameResult{Scores: scores, History: history})
+		sum.ChainLength = len(history)
+		words := make([]string, len(history))
+		for i, h := range history {
+			words[i] = h.Word
+		}
+		sum.Chain = strings.Join(wrapChain(words, 40, 1), "")
+		results = append(results, sum)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	more := len(results) > resultsPerPage
+	if more {
+		results = results[:resultsPerPage]
+	}
+	return results, more, nil
+}
+
+// indexResultWords adds a result's words to the search index as part of
+// saving it.
+func indexResultWords(tx *sql.Tx, resultID string, history []WordEntry) error {
+	words := make([]string, len(history))
+	readings := make([]string, len(history))
+	for i, h := range history {
+		words[i] = h.Word
+		readings[i] = toHiragana(h.Word)
+	}
+	_, err := tx.Exec(
+		`INSERT INTO game_results_fts (result_id, words, readings) VALUES (?, ?, ?)`,
+		resultID, strings.Join(words, " "), strings.Join(readings, " "),
+	)
+	return err
+}
+
+// HandleSearchResults returns public results matching the query filters.
+func (s *Server) HandleSearchResults(w http.ResponseWriter, r *http.Request) {
+	f, err := parseResultFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	results, more, err := s.searchResults(f)
+	if err != nil {
+		slog.Error("search results", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if results == nil {
+		results = []ResultSummary{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"results": results,
+		"page":    f.Page,
+		"hasMore": more,
+	})
+}
+
+// HandleResultsPage serves a browsable, filterable list of public results.
+func (s *Server) HandleResultsPage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f, err := parseResultFilter(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	results, more, err := s.searchResults(f)
+	if err != nil {
+		slog.Error("search results", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	pageURL := func(page int) string {
+		v := url.Values{}
+		for k, vals := range q {
+			if k != "page" && len(vals) > 0 && vals[0] != "" {
+				v.Set(k, vals[0])
+			}
+		}
+		if page > 1 {
+			v.Set("page", strconv.Itoa(page))
+		}
+		if len(v) == 0 {
+			return "/results"
+		}
+		return "/results?" + v.Encode()
+	}
+	data := struct {
+		Results  []ResultSummary
+		Filter   url.Values
+		Page     int
+		PrevURL  string
+		NextURL  string
+		Filtered bool
+	}{
+		Results:  results,
+		Filter:   q,
+		Page:     f.Page,
+		Filtered: f.Query != "" || f.Genre != "" || f.Player != "" || f.Winner != "" || f.MinChain > 0 || !f.From.IsZero() || !f.To.IsZero(),
+	}
+	if f.Page > 1 {
+		data.PrevURL = pageURL(f.Page - 1)
+	}
+	if more {
+		data.NextURL = pageURL(f.Page + 1)
+	}
+
+	tmpl, err := template.New("results.html").Funcs(template.FuncMap{
+		"date": func(t time.Time) string { return t.Local().Format("2006/01/02 15:04") },
+	}).ParseFS(templatesFS, "templates/results.html")
+	if err != nil {
+		slog.Error("parse results template", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.Execute(w, data); err != nil {
+		slog.Error("execute results template", "error", err)
+	}
+}
@@ -0,0 +1,213 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchResults(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_search.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	save := func(res *GameResult) string {
+		t.Helper()
+		id, err := server.saveGameResult(res)
+		if err != nil {
+			t.Fatalf("save result: %v", err)
+		}
+		return id
+	}
+	words := func(ws ...string) []WordEntry {
+		var h []WordEntry
+		for _, w := range ws {
+			h = append(h, WordEntry{Word: w, Player: "alice"})
+		}
+		return h
+	}
+	fruit := save(&GameResult{
+		RoomName: "fruit", Genre: "食べ物", Winner: "alice", Visibility: VisibilityPublic,
+		Scores:  map[string]int{"alice": 2, "bob": 1},
+		History: words("しりとり", "リンゴ", "ごりら"),
+	})
+	animal := save(&GameResult{
+		RoomName: "animal", Genre: "動物", Winner: "bob", Visibility: VisibilityPublic,
+		Scores:  map[string]int{"bob": 1, "carol": 0},
+		History: words("らくだ"),
+	})
+	save(&GameResult{
+		RoomName: "hidden", Visibility: VisibilityUnlisted,
+		Scores:  map[string]int{"alice": 1},
+		History: words("りんご"),
+	})
+	old := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := server.DB.Exec(`UPDATE game_results SET created_at = ? WHERE id = ?`, old, animal); err != nil {
+		t.Fatalf("backdate result: %v", err)
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		q, _ := url.ParseQuery(query)
+		f, err := parseResultFilter(q)
+		if err != nil {
+			t.Fatalf("parse %q: %v", query, err)
+		}
+		results, _, err := server.searchResults(f)
+		if err != nil {
+			t.Fatalf("search %q: %v", query, err)
+		}
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	for query, want := range map[string][]string{
+		"":                 {fruit, animal},
+		"q=りんご":            {fruit}, // katakana word found by its reading
+		"q=リンゴ":            {fruit},
+		"q=くだ":             {animal}, // shorter than a trigram
+		"q=みかん":            nil,
+		"genre=動物":         {animal},
+		"player=alice":     {fruit},
+		"winner=bob":       {animal},
+		"minChain=2":       {fruit},
+		"to=2020-05-01":    {animal},
+		"from=2021-01-01":  {fruit},
+		"q=ごりら&player=bob": {fruit},
+	} {
+		got := search(query)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("search %q: expected %v, got %v", query, want, got)
+		}
+	}
+	if _, err := parseResultFilter(url.Values{"minChain": {"x"}}); err == nil {
+		t.Error("expected invalid minChain to be rejected")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/results", server.HandleSearchResults)
+	mux.HandleFunc("GET /results", server.HandleResultsPage)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/results?q="+url.QueryEscape("らくだ"), nil))
+	var resp struct {
+		Results []ResultSummary `json:"results"`
+		HasMore bool            `json:"hasMore"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || len(resp.Results) != 1 {
+		t.Fatalf("expected one API result, got %+v (%v)", resp, err)
+	}
+	if r := resp.Results[0]; r.ChainLength != 1 || r.Chain != "らくだ" || len(r.Players) != 3 {
+		t.Errorf("unexpected summary %+v", r)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/results", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, "/results/"+fruit) || strings.Contains(body, "hidden") {
+		t.Errorf("expected page to list only public results, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/results?page=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid page to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestDeletedResultLeavesSearchIndex(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_search.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	id, err := server.saveGameResult(&GameResult{RoomName: "x", History: []WordEntry{{Word: "しりとり"}}})
+	if err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+	if _, err := server.deleteGameResult(id); err != nil {
+		t.Fatalf("delete result: %v", err)
+	}
+	var n int
+	server.DB.QueryRow(`SELECT count(*) FROM game_results_fts WHERE result_id = ?`, id).Scan(&n)
+	if n != 0 {
+		t.Errorf("expected search index entry to be deleted, found %d", n)
+	}
+}
+
+func TestFinishedGameIsListed(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_search.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("ls01", RoomSettings{Name: "listed", MinLen: 1, MaxLives: 1})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	bob := &Player{Name: "bob", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	room.AddPlayer(bob)
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+
+	// Play a word, then lose the only life on a word ending in ん.
+	first := room.Engine.CurrentTurn()
+	server.handleAnswer(room, first, "しりとり")
+	server.handleAnswer(room, room.Engine.CurrentTurn(), "りかん")
+
+	var resultID, token string
+	for len(alice.Send) > 0 {
+		var msg map[string]any
+		json.Unmarshal(<-alice.Send, &msg)
+		switch msg["type"] {
+		case "game_over":
+			resultID, _ = msg["resultId"].(string)
+		case "result_owner":
+			token, _ = msg["token"].(string)
+		}
+	}
+	if resultID == "" || token == "" {
+		t.Fatalf("expected game_over with a resultId and an owner token, got %q %q", resultID, token)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/results", server.HandleSearchResults)
+	mux.HandleFunc("POST /api/results/{id}/visibility", server.HandleSetVisibility)
+	listed := func() []string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/api/results?q="+url.QueryEscape("しりとり"), nil))
+		var resp struct {
+			Results []ResultSummary `json:"results"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode listing: %v", err)
+		}
+		var ids []string
+		for _, r := range resp.Results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+	if got := listed(); len(got) != 1 || got[0] != resultID {
+		t.Fatalf("expected the finished game to be listed, got %v", got)
+	}
+
+	req := httptest.NewRequest("POST", "/api/results/"+resultID+"/visibility",
+		strings.NewReader(`{"token":"`+token+`","visibility":"unlisted"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unlist result: %d", rec.Code)
+	}
+	if got := listed(); len(got) != 0 {
+		t.Errorf("expected an unlisted result to leave the listing, got %v", got)
+	}
+}
@@ -3,10 +3,13 @@ package srv
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 
 	"srv.exe.dev/db"
 )
@@ -16,17 +19,42 @@ type Server struct {
 	DB       *sql.DB
 	Hostname string
 	Rooms    *RoomManager
+	// JoinFailures throttles wrong passwords and unknown room IDs on join.
+	JoinFailures *FailureLimiter
+
+	admins     map[string]bool
+	resultKey  []byte
+	ogpImages  *ogpImageCache
+	httpServer *http.Server
+
+	// trustedProxies may set X-Forwarded-For; nil means defaultTrustedProxies.
+	trustedProxies []netip.Prefix
 }
 
 // New creates a new Server with database and room manager.
 func New(dbPath, hostname string) (*Server, error) {
 	srv := &Server{
-		Hostname: hostname,
-		Rooms:    NewRoomManager(),
+		Hostname:     hostname,
+		Rooms:        NewRoomManager(),
+		JoinFailures: NewFailureLimiter(joinFailureLimit),
+		ogpImages:    newOGPImageCache(ogpCacheSize),
 	}
 	if err := srv.setUpDatabase(dbPath); err != nil {
 		return nil, err
 	}
+	key, err := srv.loadOrCreateSecret(resultTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	srv.resultKey = key
+	// Built here rather than in Serve so Shutdown never races with Serve.
+	srv.httpServer = &http.Server{Handler: srv.routes()}
+	n, err := srv.RestoreSnapshots()
+	if err != nil {
+		slog.Error("restore room snapshots", "error", err)
+	} else if n > 0 {
+		slog.Info("restored rooms from snapshots", "count", n)
+	}
 	return srv, nil
 }
 
@@ -74,6 +102,7 @@ func (s *Server) HandleRoomInfo(w http.ResponseWriter, r *http.Request) {
 		"owner":       room.Owner,
 		"status":      room.Status,
 		"playerCount": len(players),
+		"hasPassword": room.Settings.HasPassword,
 		"settings":    room.Settings,
 		"players":     players,
 	}
@@ -84,17 +113,56 @@ func (s *Server) HandleRoomInfo(w http.ResponseWriter, r *http.Request) {
 }
 
 // Serve starts the HTTP server with the configured routes.
+// It returns nil once Shutdown has stopped the server, including when
+// Shutdown ran first.
 func (s *Server) Serve(addr string) error {
+	if s.Rooms.Draining() {
+		return nil
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
 	s.Rooms.StartCleanup(roomCleanupInterval, roomMaxEmptyAge)
+	s.StartSnapshots(snapshotInterval)
+	slog.Info("starting server", "addr", addr)
+	if err := s.httpServer.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// routes returns the handler for all of the server's endpoints.
+func (s *Server) routes() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /{$}", s.HandleIndex)
 	mux.HandleFunc("GET /ws", s.HandleWS)
 	mux.HandleFunc("GET /room/{id}", s.HandleRoomInfo)
-	mux.HandleFunc("POST /api/results", s.HandleSaveResult)
+	mux.HandleFunc("POST /api/results/{id}/visibility", s.HandleSetVisibility)
 	mux.HandleFunc("GET /results/{id}/ogp.svg", s.HandleOGPImage)
+	mux.HandleFunc("GET /results/{id}/ogp.png", s.HandleOGPPNG)
+	mux.HandleFunc("GET /results/{id}/timeline.svg", s.HandleTimelineSVG)
+	mux.HandleFunc("GET /results/{id}/kana.svg", s.HandleKanaGraphSVG)
+	mux.HandleFunc("GET /results/{id}/hardest.svg", s.HandleHardestMomentSVG)
 	mux.HandleFunc("GET /results/{id}", s.HandleViewResultPage)
+	mux.HandleFunc("GET /results/{id}/replay", s.HandleReplayPage)
+	mux.HandleFunc("GET /api/results/{id}/replay", s.HandleReplayData)
+	mux.HandleFunc("GET /api/results/{id}/events", s.HandleGameEvents)
+	mux.HandleFunc("GET /api/results/{id}/export", s.HandleExportResult)
+	mux.HandleFunc("GET /api/results", s.HandleSearchResults)
+	mux.HandleFunc("GET /results", s.HandleResultsPage)
+	mux.HandleFunc("GET /api/daily", s.HandleDaily)
+	mux.HandleFunc("GET /api/daily/{date}", s.HandleDaily)
+	mux.HandleFunc("GET /api/matches/{id}", s.HandleMatchResult)
+	mux.HandleFunc("GET /metrics", s.HandleMetrics)
+	mux.HandleFunc("GET /admin", s.requireAdmin(s.HandleAdminPage))
+	mux.HandleFunc("GET /admin/api/rooms", s.requireAdmin(s.HandleAdminRooms))
+	mux.HandleFunc("POST /admin/api/rooms/{id}/close", s.requireAdmin(s.HandleAdminCloseRoom))
+	mux.HandleFunc("POST /admin/api/rooms/{id}/kick/{player}", s.requireAdmin(s.HandleAdminKickPlayer))
+	mux.HandleFunc("POST /admin/api/announce", s.requireAdmin(s.HandleAdminAnnounce))
+	mux.HandleFunc("DELETE /admin/api/results/{id}", s.requireAdmin(s.HandleAdminDeleteResult))
+	mux.HandleFunc("GET /admin/api/results/export", s.requireAdmin(s.HandleAdminExportResults))
 	staticSub, _ := fs.Sub(staticFS, "static")
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticSub))))
-	slog.Info("starting server", "addr", addr)
-	return http.ListenAndServe(addr, mux)
+	return mux
 }
@@ -251,3 +251,56 @@ func TestAllowedRowsInGame(t *testing.T) {
 		t.Errorf("expected きた to be ValidatePenalty, got result=%d msg=%s", result, msg)
 	}
 }
+
+func TestMoraCount(t *testing.T) {
+	tests := []struct {
+		input    string
+		opts     MoraOptions
+		expected int
+	}{
+		{"きゃく", MoraOptions{}, 2},
+		{"しゅっちょう", MoraOptions{Sokuon: true, Choon: true}, 4},
+		{"しゅっちょう", MoraOptions{}, 3},
+		{"らーめん", MoraOptions{Sokuon: true, Choon: true}, 4},
+		{"らーめん", MoraOptions{Sokuon: true}, 3},
+		{"ふぁいる", MoraOptions{}, 3},
+		{"ゔぁいおりん", MoraOptions{}, 5},
+		{"がっこう", MoraOptions{Sokuon: true}, 4},
+		{"がっこう", MoraOptions{}, 3},
+		{"ゃ", MoraOptions{}, 1}, // nothing to merge with
+		{"ーあ", MoraOptions{}, 1},
+	}
+	for _, tt := range tests {
+		if got := moraCount(tt.input, tt.opts); got != tt.expected {
+			t.Errorf("moraCount(%q, %+v) = %d, want %d", tt.input, tt.opts, got, tt.expected)
+		}
+	}
+}
+
+func TestLengthInMora(t *testing.T) {
+	settings := RoomSettings{MinLen: 3, MaxLen: 4, LengthUnit: LengthMora}
+	room := &Room{
+		Settings: settings,
+		Players: map[string]*Player{
+			"test": {Name: "test", Score: 0, Lives: 3, Send: make(chan []byte, 256)},
+		},
+		Status: "playing",
+	}
+	room.Engine = NewGameEngine(settings, []string{"test"}, nil)
+
+	// きゃく is 3 characters but only 2 morae
+	result, msg := room.ValidateAndSubmitWord("きゃく", "test")
+	if result != ValidateRejected || msg != "3音以上で入力してください" {
+		t.Errorf("expected きゃく to be too short in mora, got result=%d msg=%s", result, msg)
+	}
+	// ちょこれーと is 6 characters and 5 morae
+	result, msg = room.ValidateAndSubmitWord("ちょこれーと", "test")
+	if result != ValidateRejected || msg != "4音以下で入力してください" {
+		t.Errorf("expected ちょこれーと to be too long in mora, got result=%d msg=%s", result, msg)
+	}
+	// しゃっこう is 5 characters but 4 morae
+	result, msg = room.ValidateAndSubmitWord("しゃっこう", "test")
+	if result != ValidateOK {
+		t.Errorf("expected しゃっこう to fit in 4 morae, got result=%d msg=%s", result, msg)
+	}
+}
@@ -0,0 +1,108 @@
+package srv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// shutdownNoticeInterval is how often the server_shutdown countdown is re-sent.
+	shutdownNoticeInterval = 10 * time.Second
+	// shutdownSnapshotMargin is reserved at the end of the drain deadline for
+	// snapshotting unfinished rooms and closing connections.
+	shutdownSnapshotMargin = 3 * time.Second
+	// defaultDrainTimeout is used when Shutdown's context has no deadline.
+	defaultDrainTimeout = 60 * time.Second
+)
+
+// Shutdown drains the server: new rooms are refused, every room is told the
+// server is going down, in-progress games get until the context deadline to
+// finish, unfinished rooms are snapshotted for restore, and finally all
+// connections, background goroutines and the database are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultDrainTimeout)
+	}
+	s.Rooms.SetDraining(true)
+	slog.Info("draining rooms before shutdown", "deadline", deadline)
+
+	s.drainRooms(ctx, deadline.Add(-shutdownSnapshotMargin))
+
+	s.Rooms.StopCleanup()
+	for _, room := range s.Rooms.AllRooms() {
+		room.StopTimer()
+	}
+	var errs []error
+	if err := s.SaveSnapshots(); err != nil {
+		errs = append(errs, fmt.Errorf("save snapshots: %w", err))
+	}
+	s.closeConnections()
+
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+	}
+	if err := s.DB.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close db: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// drainRooms broadcasts the shutdown countdown until every game has finished
+// or the drain deadline passes.
+func (s *Server) drainRooms(ctx context.Context, until time.Time) {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	var lastNotice time.Time
+	for {
+		remaining := time.Until(until)
+		if remaining <= 0 || s.Rooms.PlayingCount() == 0 {
+			return
+		}
+		if time.Since(lastNotice) >= shutdownNoticeInterval {
+			s.broadcastShutdownNotice(remaining)
+			lastNotice = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// broadcastShutdownNotice tells every room how long until the server stops.
+func (s *Server) broadcastShutdownNotice(remaining time.Duration) {
+	secs := int(remaining.Round(time.Second) / time.Second)
+	msg := mustMarshal(map[string]any{
+		"type":      "server_shutdown",
+		"countdown": secs,
+		"message":   fmt.Sprintf("サーバーはメンテナンスのため%d秒後に停止します。進行中のゲームは再起動後に再開できます", secs),
+	})
+	for _, room := range s.Rooms.AllRooms() {
+		room.Broadcast(msg)
+	}
+}
+
+// closeConnections sends a "service restart" close frame to every player.
+// Rooms stay in the manager so their final snapshot is intact.
+func (s *Server) closeConnections() {
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server shutdown")
+	deadline := time.Now().Add(writeWait)
+	for _, room := range s.Rooms.AllRooms() {
+		room.mu.Lock()
+		for _, p := range room.Players {
+			if p.Conn == nil {
+				continue
+			}
+			p.Conn.WriteControl(websocket.CloseMessage, closeMsg, deadline)
+			p.Conn.Close()
+		}
+		room.mu.Unlock()
+	}
+}
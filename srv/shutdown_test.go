@@ -0,0 +1,109 @@
+package srv
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShutdownDrainsAndSnapshots(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_shutdown.sqlite3")
+	server, err := New(dbPath, "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	room := server.Rooms.CreateRoom("live01", RoomSettings{Name: "live", MinLen: 1})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownSnapshotMargin+1500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected shutdown to wait for the playing room, returned after %v", elapsed)
+	}
+	if !server.Rooms.Draining() {
+		t.Error("expected room manager to be draining")
+	}
+
+	var notice map[string]any
+	select {
+	case msg := <-alice.Send:
+		json.Unmarshal(msg, &notice)
+	default:
+	}
+	if notice["type"] != "server_shutdown" {
+		t.Errorf("expected server_shutdown notice, got %v", notice)
+	}
+
+	restarted, err := New(dbPath, "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to restart server: %v", err)
+	}
+	if got := restarted.Rooms.GetRoom("live01"); got == nil || got.Status != "playing" {
+		t.Error("expected the unfinished room to be snapshotted and restored")
+	}
+}
+
+func TestShutdownReturnsOnceGamesFinish(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_shutdown.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("done01", RoomSettings{Name: "done"})
+	server.setUpRoom(room)
+	room.Status = "finished"
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	start := time.Now()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected immediate shutdown without playing rooms, took %v", elapsed)
+	}
+}
+
+func TestShutdownStopsServe(t *testing.T) {
+	for _, early := range []bool{false, true} {
+		server, err := New(filepath.Join(t.TempDir(), "test_serve.sqlite3"), "test-hostname")
+		if err != nil {
+			t.Fatalf("failed to create server: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if early {
+			// A signal can arrive before the serving goroutine gets going.
+			if err := server.Shutdown(ctx); err != nil {
+				t.Fatalf("shutdown: %v", err)
+			}
+		}
+		errc := make(chan error, 1)
+		go func() { errc <- server.Serve("127.0.0.1:0") }()
+		if !early {
+			if err := server.Shutdown(ctx); err != nil {
+				t.Fatalf("shutdown: %v", err)
+			}
+		}
+		select {
+		case err := <-errc:
+			if err != nil {
+				t.Errorf("expected Serve to return nil after Shutdown, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("expected Serve to return after Shutdown (early=%v)", early)
+		}
+		cancel()
+	}
+}
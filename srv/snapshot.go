@@ -0,0 +1,218 @@
+package srv
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// snapshotInterval is how often live rooms are written to the database.
+	snapshotInterval = 10 * time.Second
+	// snapshotMaxAge is how old a snapshot may be and still be restored on startup.
+	snapshotMaxAge = 30 * time.Minute
+)
+
+// RoomSnapshot is the persisted state of a live room, written periodically so
+// games survive a server restart.
+type RoomSnapshot struct {
+	ID           string        `json:"id"`
+	Owner        string        `json:"owner"`
+	Settings     RoomSettings  `json:"settings"`
+	Status       string        `json:"status"`
+	PasswordSalt []byte        `json:"passwordSalt,omitempty"`
+	PasswordHash []byte        `json:"passwordHash,omitempty"`
+	Game         *GameSnapshot `json:"game,omitempty"`
+	Vote         *PendingVote  `json:"vote,omitempty"`
+	TimeLeft     int           `json:"timeLeft,omitempty"`
+	Match        *MatchSummary `json:"match,omitempty"`
+	Daily        *DailyPuzzle  `json:"daily,omitempty"`
+	SavedAt      time.Time     `json:"savedAt"`
+}
+
+// Snapshot captures the room's persistent state. The pending vote is read
+// before taking r.mu: the vote manager calls back into the room while holding
+// its own lock.
+func (r *Room) Snapshot() RoomSnapshot {
+	var vote *PendingVote
+	if r.Votes != nil {
+		vote = r.Votes.ExportPending()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snap := RoomSnapshot{
+		ID:       r.ID,
+		Owner:    r.Owner,
+		Settings: r.Settings,
+		Status:   r.Status,
+		Daily:    r.Daily,
+		Vote:     vote,
+		SavedAt:  time.Now().UTC(),
+	}
+	if r.password != nil {
+		snap.PasswordSalt = r.password.salt
+		snap.PasswordHash = r.password.hash
+	}
+	if r.Engine != nil {
+		game := r.Engine.ExportState()
+		snap.Game = &game
+	}
+	if r.Status == "playing" && r.Settings.TimeLimit > 0 {
+		snap.TimeLeft = r.resumeTimeLeft
+		if r.Timer != nil && snap.TimeLeft == 0 {
+			snap.TimeLeft = r.Timer.TimeLeft()
+		}
+	}
+	if r.Match != nil {
+		match := r.Match.Summary()
+		snap.Match = &match
+	}
+	return snap
+}
+
+// restoreRoom rebuilds a room from a snapshot. The room starts empty; its
+// players rejoin by name and pick up their saved lives and scores.
+func (s *Server) restoreRoom(snap RoomSnapshot) *Room {
+	room := &Room{
+		ID:       snap.ID,
+		Owner:    snap.Owner,
+		Settings: snap.Settings,
+		Players:  make(map[string]*Player),
+		Status:   snap.Status,
+		Daily:    snap.Daily,
+	}
+	if len(snap.PasswordHash) > 0 {
+		room.password = &roomPassword{salt: snap.PasswordSalt, hash: snap.PasswordHash}
+	}
+	s.setUpRoom(room)
+	if snap.Game != nil {
+		room.Engine = RestoreGameEngine(room.Settings, *snap.Game, func() {
+			if room.Timer != nil {
+				room.Timer.Reset()
+			}
+		})
+	}
+	if snap.Vote != nil && room.Status == "playing" {
+		room.Votes.RestorePending(snap.Vote)
+	}
+	if snap.Match != nil {
+		room.Match = RestoreMatchState(*snap.Match)
+	}
+	if room.Status == "playing" && room.Settings.TimeLimit > 0 {
+		room.resumeTimeLeft = snap.TimeLeft
+		if room.resumeTimeLeft <= 0 {
+			room.resumeTimeLeft = room.Settings.TimeLimit
+		}
+	}
+	now := time.Now()
+	room.EmptySince = &now
+	return room
+}
+
+// ResumeTimer restarts the turn timer of a restored room once a player is
+// back. A bomb mode fuse isn't saved, so a fresh one is lit.
+func (r *Room) ResumeTimer() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Status == "playing" && r.Settings.Bomb && r.Fuse != nil {
+		r.Fuse.Start()
+	}
+	if r.resumeTimeLeft <= 0 || r.Status != "playing" || r.Timer == nil {
+		return
+	}
+	r.Timer.Resume(r.Settings.TimeLimit, r.resumeTimeLeft)
+	r.resumeTimeLeft = 0
+}
+
+// SaveSnapshots writes every live room to the database and removes
+// snapshots of rooms that no longer exist.
+func (s *Server) SaveSnapshots() error {
+	defer metrics.dbWriteLatency.ObserveSince(time.Now(), "save_snapshots")
+	rooms := s.Rooms.AllRooms()
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin snapshot tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM room_snapshots`); err != nil {
+		return fmt.Errorf("clear snapshots: %w", err)
+	}
+	for _, room := range rooms {
+		snap := room.Snapshot()
+		data, err := json.Marshal(snap)
+		if err != nil {
+			return fmt.Errorf("marshal snapshot %s: %w", snap.ID, err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO room_snapshots (room_id, status, snapshot_json, updated_at) VALUES (?, ?, ?, ?)`,
+			snap.ID, snap.Status, string(data), snap.SavedAt,
+		); err != nil {
+			return fmt.Errorf("insert snapshot %s: %w", snap.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// RestoreSnapshots loads recent room snapshots into the room manager.
+// Returns the number of rooms restored.
+func (s *Server) RestoreSnapshots() (int, error) {
+	rows, err := s.DB.Query(
+		`SELECT snapshot_json FROM room_snapshots WHERE updated_at >= ?`,
+		time.Now().UTC().Add(-snapshotMaxAge),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("query snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	restored := 0
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return restored, fmt.Errorf("scan snapshot: %w", err)
+		}
+		var snap RoomSnapshot
+		if err := json.Unmarshal([]byte(data), &snap); err != nil {
+			slog.Warn("skip unreadable room snapshot", "error", err)
+			continue
+		}
+		if snap.ID == "" || s.Rooms.GetRoom(snap.ID) != nil {
+			continue
+		}
+		room := s.restoreRoom(snap)
+		s.Rooms.AddRoom(room)
+		if room.Votes.HasPendingVote() {
+			// The original vote timer died with the old process.
+			go func() {
+				time.Sleep(voteTimeout)
+				resolved, result := room.ForceResolveVote()
+				if resolved {
+					s.broadcastVoteResult(room, result)
+				}
+			}()
+		}
+		restored++
+	}
+	return restored, rows.Err()
+}
+
+// StartSnapshots starts a background goroutine that periodically saves
+// room snapshots until the room manager is stopped.
+func (s *Server) StartSnapshots(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.Rooms.done:
+				return
+			case <-ticker.C:
+				if err := s.SaveSnapshots(); err != nil {
+					slog.Error("save room snapshots", "error", err)
+				}
+			}
+		}
+	}()
+}
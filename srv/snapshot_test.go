@@ -0,0 +1,142 @@
+package srv
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestoreAcrossRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_snapshot.sqlite3")
+	server, err := New(dbPath, "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	room := server.Rooms.CreateRoom("abc123", RoomSettings{Name: "live", MinLen: 1, TimeLimit: 30, Rounds: 3, Password: "pw"})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	room.AddPlayer(&Player{Name: "alice", Send: make(chan []byte, 256)})
+	room.AddPlayer(&Player{Name: "bob", Send: make(chan []byte, 256)})
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	room.StopTimer()
+	if result, msg := room.ValidateAndSubmitWord("しりとり", "alice"); result != ValidateOK {
+		t.Fatalf("expected しりとり to be accepted: %s", msg)
+	}
+	room.Engine.ApplyPenalty("bob", "test")
+
+	if err := server.SaveSnapshots(); err != nil {
+		t.Fatalf("save snapshots: %v", err)
+	}
+
+	// Simulate a restart with a fresh server on the same database.
+	restarted, err := New(dbPath, "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to restart server: %v", err)
+	}
+	got := restarted.Rooms.GetRoom("abc123")
+	if got == nil {
+		t.Fatal("expected room to be restored")
+	}
+	if got.Status != "playing" || got.Owner != "alice" {
+		t.Errorf("unexpected restored room: status=%q owner=%q", got.Status, got.Owner)
+	}
+	if !got.CheckPassword("pw") || got.CheckPassword("nope") {
+		t.Error("expected room password to be restored")
+	}
+	if got.Match == nil || got.Match.ID != room.Match.ID || got.Match.BestOf != 3 {
+		t.Error("expected match to be restored")
+	}
+
+	history, currentWord, turnOrder, _ := got.Engine.Snapshot()
+	if len(history) != 1 || currentWord != "しりとり" {
+		t.Errorf("unexpected restored history=%v currentWord=%q", history, currentWord)
+	}
+	if len(turnOrder) != 2 || got.Engine.CurrentTurn() != "bob" {
+		t.Errorf("unexpected restored turn: order=%v current=%q", turnOrder, got.Engine.CurrentTurn())
+	}
+	if !got.Engine.UsedWords["しりとり"] {
+		t.Error("expected used words to be restored")
+	}
+
+	// Rejoining keeps the saved lives instead of resetting them.
+	got.AddPlayer(&Player{Name: "bob", Send: make(chan []byte, 256)})
+	if lives := got.Engine.GetPlayerLives("bob"); lives != 2 {
+		t.Errorf("expected bob to keep 2 lives after rejoining, got %d", lives)
+	}
+	if scores := got.Engine.GetScores(); scores["alice"] != 1 {
+		t.Errorf("expected alice score 1, got %d", scores["alice"])
+	}
+	got.ResumeTimer()
+	got.StopTimer()
+}
+
+func TestSaveSnapshotsDropsRemovedRooms(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_snapshot.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("gone01", RoomSettings{Name: "gone"})
+	server.setUpRoom(room)
+	if err := server.SaveSnapshots(); err != nil {
+		t.Fatalf("save snapshots: %v", err)
+	}
+	server.Rooms.RemoveRoom("gone01")
+	if err := server.SaveSnapshots(); err != nil {
+		t.Fatalf("save snapshots: %v", err)
+	}
+	var count int
+	if err := server.DB.QueryRow(`SELECT COUNT(*) FROM room_snapshots`).Scan(&count); err != nil {
+		t.Fatalf("count snapshots: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no snapshots after room removal, got %d", count)
+	}
+}
+
+func TestSnapshotDuringVote(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_snapshot.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("vote01", RoomSettings{Name: "vote", MinLen: 1})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	room.AddPlayer(&Player{Name: "alice", Send: make(chan []byte, 256)})
+	room.AddPlayer(&Player{Name: "bob", Send: make(chan []byte, 256)})
+	if err := room.StartGame(); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	room.StopTimer()
+
+	// Snapshotting while votes are cast must not deadlock.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 5000 {
+				room.Snapshot()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 5000 {
+				room.Votes.StartWordVote("ねこ", "ねこ", "alice", "test")
+				room.Votes.CastVote("alice", true)
+				room.Votes.CastVote("bob", true)
+			}
+		}()
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("snapshot and vote deadlocked")
+	}
+}
@@ -35,7 +35,25 @@ func (tm *TimerManager) Start(timeLimit int) {
 	}
 	tm.left = timeLimit
 	tm.cancel = make(chan struct{})
-	go tm.run()
+	go tm.run(tm.cancel)
+}
+
+// Resume restarts the countdown with left seconds remaining on a turn of
+// timeLimit seconds (used when restoring a room from a snapshot).
+func (tm *TimerManager) Resume(timeLimit, left int) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.stopLocked()
+	tm.timeLimit = timeLimit
+	if timeLimit <= 0 {
+		return
+	}
+	if left <= 0 || left > timeLimit {
+		left = timeLimit
+	}
+	tm.left = left
+	tm.cancel = make(chan struct{})
+	go tm.run(tm.cancel)
 }
 
 // Reset resets the countdown to the configured time limit.
@@ -47,6 +65,16 @@ func (tm *TimerManager) Reset() {
 	}
 }
 
+// Add gives the current turn extra seconds, returning the time left.
+func (tm *TimerManager) Add(seconds int) int {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if tm.cancel != nil {
+		tm.left += seconds
+	}
+	return tm.left
+}
+
 // Stop cancels the running timer.
 func (tm *TimerManager) Stop() {
 	tm.mu.Lock()
@@ -72,16 +100,24 @@ func (tm *TimerManager) TimeLeft() int {
 	return tm.left
 }
 
-func (tm *TimerManager) run() {
+// run counts down until cancel is closed or the timer expires.
+// cancel is passed in so a restarted timer doesn't race on tm.cancel.
+func (tm *TimerManager) run(cancel chan struct{}) {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-tm.cancel:
+		case <-cancel:
 			return
 		case <-ticker.C:
 			tm.mu.Lock()
+			select {
+			case <-cancel:
+				tm.mu.Unlock()
+				return
+			default:
+			}
 			tm.left--
 			left := tm.left
 			if left <= 0 {
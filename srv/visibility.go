@@ -0,0 +1,141 @@
+package srv
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+)
+
+// Result visibility levels. Public results may be listed and indexed,
+// unlisted ones are reachable by link only, and private ones only with the
+// owner's token. Results start public, as they were before owners could hide
+// them.
+const (
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+
+	defaultVisibility = VisibilityPublic
+)
+
+// resultTokenSecret names the HMAC key used to sign result owner tokens.
+const resultTokenSecret = "result_token"
+
+func validVisibility(v string) bool {
+	switch v {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// loadOrCreateSecret returns a named random key stored in the database,
+// creating it on first use so signatures survive restarts.
+func (s *Server) loadOrCreateSecret(name string) ([]byte, error) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	if _, err := s.DB.Exec(
+		`INSERT OR IGNORE INTO server_secrets (name, value) VALUES (?, ?)`, name, key,
+	); err != nil {
+		return nil, fmt.Errorf("create secret %s: %w", name, err)
+	}
+	var stored []byte
+	if err := s.DB.QueryRow(`SELECT value FROM server_secrets WHERE name = ?`, name).Scan(&stored); err != nil {
+		return nil, fmt.Errorf("load secret %s: %w", name, err)
+	}
+	return stored, nil
+}
+
+// resultToken signs a result ID. The room owner receives it at game over and
+// presents it to change the result's visibility or to view it while private.
+func (s *Server) resultToken(id string) string {
+	mac := hmac.New(sha256.New, s.resultKey)
+	mac.Write([]byte("result:" + id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) validResultToken(id, token string) bool {
+	if token == "" {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(s.resultToken(id)))
+}
+
+// loadVisibleResult loads a result for a viewer. Private results are reported
+// as missing unless the request carries the owner token (?token=).
+func (s *Server) loadVisibleResult(r *http.Request, id string) (*GameResult, error) {
+	res, err := s.loadResult(id)
+	if err != nil {
+		return nil, err
+	}
+	if res.Visibility == VisibilityPrivate && !s.validResultToken(id, r.URL.Query().Get("token")) {
+		return nil, sql.ErrNoRows
+	}
+	return res, nil
+}
+
+// sendResultToken privately tells the room owner how to manage a saved result.
+// The client keeps the token so the owner can change visibility later from the
+// result page.
+func (s *Server) sendResultToken(room *Room, id, visibility string) {
+	msg := mustMarshal(map[string]any{
+		"type":       "result_owner",
+		"resultId":   id,
+		"token":      s.resultToken(id),
+		"visibility": visibility,
+	})
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	if p, ok := room.Players[room.Owner]; ok {
+		select {
+		case p.Send <- msg:
+		default:
+			metrics.broadcastsDropped.Inc("direct")
+		}
+	}
+}
+
+// HandleSetVisibility lets the owner of a result publish, unlist or hide it.
+func (s *Server) HandleSetVisibility(w http.ResponseWriter, r *http.Request) {
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+		http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	var req struct {
+		Token      string `json:"token"`
+		Visibility string `json:"visibility"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if !validVisibility(req.Visibility) {
+		http.Error(w, "visibility must be public, unlisted or private", http.StatusBadRequest)
+		return
+	}
+	id := r.PathValue("id")
+	if !s.validResultToken(id, req.Token) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	res, err := s.DB.Exec(`UPDATE game_results SET visibility = ? WHERE id = ?`, req.Visibility, id)
+	if err != nil {
+		slog.Error("set result visibility", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"id": id, "visibility": req.Visibility})
+}
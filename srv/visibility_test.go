@@ -0,0 +1,114 @@
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResultOwnerControlsVisibility(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_visibility.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("vis01", RoomSettings{Name: "vis", MinLen: 1})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	bob := &Player{Name: "bob", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	room.AddPlayer(bob)
+
+	msg := room.OnGameOver(room, map[string]any{"type": "game_over", "winner": "alice"})
+	id, _ := msg["resultId"].(string)
+	if id == "" {
+		t.Fatal("expected the game over callback to save a result")
+	}
+
+	// Only the owner is told the token.
+	var owner map[string]any
+	json.Unmarshal(<-alice.Send, &owner)
+	if owner["type"] != "result_owner" || owner["resultId"] != id || owner["visibility"] != VisibilityPublic {
+		t.Fatalf("unexpected owner message: %v", owner)
+	}
+	token, _ := owner["token"].(string)
+	if len(bob.Send) != 0 {
+		t.Error("expected no token to be sent to other players")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/results/{id}/visibility", server.HandleSetVisibility)
+	mux.HandleFunc("GET /results/{id}", server.HandleViewResultPage)
+	setVisibility := func(token, visibility string) int {
+		body := `{"token":"` + token + `","visibility":"` + visibility + `"}`
+		req := httptest.NewRequest("POST", "/api/results/"+id+"/visibility", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	view := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/results/"+id+query, nil))
+		return rec
+	}
+
+	if rec := view(""); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "noindex") {
+		t.Errorf("expected a new result to be public, got %d", rec.Code)
+	}
+	if code := setVisibility(token, VisibilityUnlisted); code != http.StatusOK {
+		t.Fatalf("expected owner to unlist result, got %d", code)
+	}
+	if rec := view(""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `name="robots" content="noindex"`) {
+		t.Errorf("expected unlisted result to be viewable but not indexed, got %d", rec.Code)
+	}
+	if code := setVisibility("forged", VisibilityPrivate); code != http.StatusForbidden {
+		t.Errorf("expected forged token to be rejected, got %d", code)
+	}
+	if code := setVisibility(token, "secret"); code != http.StatusBadRequest {
+		t.Errorf("expected unknown visibility to be rejected, got %d", code)
+	}
+	if code := setVisibility(token, VisibilityPrivate); code != http.StatusOK {
+		t.Fatalf("expected owner to make result private, got %d", code)
+	}
+	if rec := view(""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected private result to be hidden, got %d", rec.Code)
+	}
+	if rec := view("?token=" + token); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "/replay?token="+token) {
+		t.Errorf("expected owner to view private result with token, got %d", rec.Code)
+	}
+	if code := setVisibility(token, VisibilityPublic); code != http.StatusOK {
+		t.Fatalf("expected owner to publish result, got %d", code)
+	}
+	if rec := view(""); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "noindex") {
+		t.Errorf("expected public result to be indexable, got %d", rec.Code)
+	}
+}
+
+func TestResultTokenKeySurvivesRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test_visibility.sqlite3")
+	server, err := New(dbPath, "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	restarted, err := New(dbPath, "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to restart server: %v", err)
+	}
+	if !restarted.validResultToken("abc", server.resultToken("abc")) {
+		t.Error("expected tokens to stay valid across restarts")
+	}
+	other, err := New(filepath.Join(t.TempDir(), "other.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if other.validResultToken("abc", server.resultToken("abc")) {
+		t.Error("expected tokens to be signed with a per-database key")
+	}
+	if server.validResultToken("abc", "") {
+		t.Error("expected empty token to be invalid")
+	}
+}
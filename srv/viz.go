@@ -0,0 +1,393 @@
+package srv
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// vizPlayerColors are assigned to players in order of their first word.
+var vizPlayerColors = []string{
+	"#c23a22", "#3d6b5e", "#b8860b", "#4a5a8a",
+	"#8a4a7a", "#5a7a3a", "#a0522d", "#2f6f8f",
+}
+
+// chainStep is one accepted word with when it was played.
+type chainStep struct {
+	Word   string
+	Player string
+	// At is the time since the game started.
+	At time.Duration
+	// Think is the time since the previous word, or since the start for the
+	// first word.
+	Think time.Duration
+}
+
+// chainSteps times every accepted word of a result. The game start comes from
+// the event log when there is one, otherwise from the first word.
+func chainSteps(result *GameResult) []chainStep {
+	var start time.Time
+	for _, ev := range result.Events {
+		if ev.Type == EventStart {
+			start = ev.Time
+			break
+		}
+	}
+	steps := make([]chainStep, len(result.History))
+	prev := start
+	for i, h := range result.History {
+		t, err := time.Parse(time.RFC3339, h.Time)
+		if err != nil {
+			t = prev
+		}
+		if start.IsZero() {
+			start, prev = t, t
+		}
+		steps[i] = chainStep{Word: h.Word, Player: h.Player, At: t.Sub(start)}
+		if !prev.IsZero() && t.After(prev) {
+			steps[i].Think = t.Sub(prev)
+		}
+		prev = t
+	}
+	return steps
+}
+
+// hardestMoment returns the index of the word that took longest to find, or
+// -1 when no word took measurable time.
+func hardestMoment(steps []chainStep) int {
+	best := -1
+	for i, st := range steps {
+		if st.Think > 0 && (best < 0 || st.Think > steps[best].Think) {
+			best = i
+		}
+	}
+	return best
+}
+
+// kanaNode is a kana that linked two words.
+type kanaNode struct {
+	Kana rune
+	// Forced counts the words that ended on this kana, forcing the next
+	// player to start with it.
+	Forced int
+	// Started counts the words that started with this kana.
+	Started int
+}
+
+// kanaLink counts the words that started with From and ended with To.
+type kanaLink struct {
+	From, To rune
+	Count    int
+}
+
+// maxKanaNodes keeps the transition graph readable for long games.
+const maxKanaNodes = 24
+
+// kanaTransitions builds the graph of first-to-last kana of every word,
+// keeping the most used kana when there are too many to draw.
+func kanaTransitions(history []WordEntry) ([]kanaNode, []kanaLink) {
+	nodes := make(map[rune]*kanaNode)
+	node := func(r rune) *kanaNode {
+		n, ok := nodes[r]
+		if !ok {
+			n = &kanaNode{Kana: r}
+			nodes[r] = n
+		}
+		return n
+	}
+	links := make(map[[2]rune]int)
+	for _, h := range history {
+		hira := toHiragana(h.Word)
+		first, last := getFirstChar(hira), getLastChar(hira)
+		if first == 0 || last == 0 {
+			continue
+		}
+		node(first).Started++
+		node(last).Forced++
+		links[[2]rune{first, last}]++
+	}
+
+	var list []kanaNode
+	for _, n := range nodes {
+		list = append(list, *n)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		ui, uj := list[i].Forced+list[i].Started, list[j].Forced+list[j].Started
+		if ui != uj {
+			return ui > uj
+		}
+		return list[i].Kana < list[j].Kana
+	})
+	if len(list) > maxKanaNodes {
+		list = list[:maxKanaNodes]
+	}
+	kept := make(map[rune]bool, len(list))
+	for _, n := range list {
+		kept[n.Kana] = true
+	}
+	// Draw kana in gojūon order around the circle.
+	sort.Slice(list, func(i, j int) bool { return list[i].Kana < list[j].Kana })
+
+	var out []kanaLink
+	for k, c := range links {
+		if kept[k[0]] && kept[k[1]] {
+			out = append(out, kanaLink{From: k[0], To: k[1], Count: c})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].From != out[j].From {
+			return out[i].From < out[j].From
+		}
+		return out[i].To < out[j].To
+	})
+	return list, out
+}
+
+// vizPlayers lists the players of a result in order of their first word,
+// followed by players who never played a word.
+func vizPlayers(result *GameResult) []string {
+	seen := make(map[string]bool)
+	var players []string
+	for _, h := range result.History {
+		if !seen[h.Player] {
+			seen[h.Player] = true
+			players = append(players, h.Player)
+		}
+	}
+	var rest []string
+	for name := range result.Scores {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(players, rest...)
+}
+
+func formatClock(d time.Duration) string {
+	s := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// HandleTimelineSVG draws which player played each word over time.
+func (s *Server) HandleTimelineSVG(w http.ResponseWriter, r *http.Request) {
+	result, err := s.loadVisibleResult(r, r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeSVG(w, result, timelineSVG(result))
+}
+
+func timelineSVG(result *GameResult) string {
+	const (
+		width     = 600
+		left      = 110
+		right     = 580
+		top       = 40
+		laneH     = 36
+		axisSpace = 36
+	)
+	players := vizPlayers(result)
+	steps := chainSteps(result)
+	height := top + len(players)*laneH + axisSpace
+
+	var total time.Duration
+	if len(steps) > 0 {
+		total = steps[len(steps)-1].At
+	}
+	// Games without timestamps are spread evenly by word number.
+	xOf := func(i int) float64 {
+		if total > 0 {
+			return left + float64(steps[i].At)/float64(total)*(right-left)
+		}
+		if len(steps) < 2 {
+			return left
+		}
+		return left + float64(i)/float64(len(steps)-1)*(right-left)
+	}
+	lane := make(map[string]int, len(players))
+	counts := make(map[string]int)
+	for i, p := range players {
+		lane[p] = i
+	}
+	for _, st := range steps {
+		counts[st.Player]++
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">
+  <rect width="%d" height="%d" fill="#faf7f0"/>
+  <text x="20" y="24" font-size="14" font-weight="700" fill="#2c2420">プレイヤーごとの回答</text>
+`, width, height, width, height, width, height)
+
+	for i, p := range players {
+		y := top + i*laneH
+		color := vizPlayerColors[i%len(vizPlayerColors)]
+		if i%2 == 0 {
+			fmt.Fprintf(&b, `  <rect x="0" y="%d" width="%d" height="%d" fill="#ede8dc" opacity="0.5"/>`+"\n", y, width, laneH)
+		}
+		fmt.Fprintf(&b, `  <text x="20" y="%d" font-size="13" fill="%s">%s（%d）</text>`+"\n",
+			y+laneH/2+5, color, svgEsc(p), counts[p])
+	}
+	for i, st := range steps {
+		li, ok := lane[st.Player]
+		if !ok {
+			continue
+		}
+		cy := top + li*laneH + laneH/2
+		fmt.Fprintf(&b, `  <circle cx="%.1f" cy="%d" r="6" fill="%s"><title>%d. %s（%s）</title></circle>`+"\n",
+			xOf(i), cy, vizPlayerColors[li%len(vizPlayerColors)], i+1, svgEsc(st.Word), formatClock(st.At))
+	}
+
+	axisY := top + len(players)*laneH + 8
+	fmt.Fprintf(&b, `  <line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#d8d0c4"/>`+"\n", left, axisY, right, axisY)
+	endLabel := fmt.Sprintf("%d語", len(steps))
+	startLabel := "1語目"
+	if total > 0 {
+		startLabel, endLabel = "0:00", formatClock(total)
+	}
+	fmt.Fprintf(&b, `  <text x="%d" y="%d" font-size="11" fill="#8a7e72">%s</text>`+"\n", left, axisY+18, startLabel)
+	fmt.Fprintf(&b, `  <text x="%d" y="%d" font-size="11" fill="#8a7e72" text-anchor="end">%s</text>`+"\n", right, axisY+18, endLabel)
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+// HandleKanaGraphSVG draws the kana transitions of a game: each arrow is words
+// that started with one kana and ended with another, and larger circles are
+// kana players were forced to start with more often.
+func (s *Server) HandleKanaGraphSVG(w http.ResponseWriter, r *http.Request) {
+	result, err := s.loadVisibleResult(r, r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeSVG(w, result, kanaGraphSVG(result))
+}
+
+func kanaGraphSVG(result *GameResult) string {
+	const (
+		size   = 600
+		center = 310.0
+		radius = 220.0
+	)
+	nodes, links := kanaTransitions(result.History)
+
+	type point struct{ X, Y, R float64 }
+	pos := make(map[rune]point, len(nodes))
+	maxForced := 0
+	for _, n := range nodes {
+		maxForced = max(maxForced, n.Forced)
+	}
+	for i, n := range nodes {
+		angle := 2*math.Pi*float64(i)/float64(len(nodes)) - math.Pi/2
+		pos[n.Kana] = point{
+			X: center + radius*math.Cos(angle),
+			Y: center + radius*math.Sin(angle),
+			R: 12 + 2*float64(min(n.Forced, 8)),
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">
+  <defs>
+    <marker id="arrow" viewBox="0 0 10 10" refX="9" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse">
+      <path d="M0,0 L10,5 L0,10 z" fill="#8a7e72"/>
+    </marker>
+  </defs>
+  <rect width="%d" height="%d" fill="#faf7f0"/>
+  <text x="20" y="28" font-size="14" font-weight="700" fill="#2c2420">かなの遷移（大きい円ほど多く回ってきた文字）</text>
+`, size, size+20, size, size+20, size, size+20)
+
+	if len(nodes) == 0 {
+		fmt.Fprintf(&b, `  <text x="%d" y="%.0f" font-size="14" fill="#8a7e72" text-anchor="middle">（なし）</text>`+"\n", size/2, center)
+		b.WriteString("</svg>")
+		return b.String()
+	}
+
+	for _, l := range links {
+		from, to := pos[l.From], pos[l.To]
+		width := 1 + math.Min(float64(l.Count-1), 4)
+		title := fmt.Sprintf("%c→%c ×%d", l.From, l.To, l.Count)
+		if l.From == l.To {
+			// Loop outward from the node, away from the centre.
+			dx, dy := from.X-center, from.Y-center
+			d := math.Hypot(dx, dy)
+			ux, uy := dx/d, dy/d
+			cx, cy := from.X+ux*(from.R+10), from.Y+uy*(from.R+10)
+			fmt.Fprintf(&b, `  <circle cx="%.1f" cy="%.1f" r="10" fill="none" stroke="#8a7e72" stroke-width="%.0f" opacity="0.6"><title>%s</title></circle>`+"\n",
+				cx, cy, width, title)
+			continue
+		}
+		dx, dy := to.X-from.X, to.Y-from.Y
+		d := math.Hypot(dx, dy)
+		ux, uy := dx/d, dy/d
+		x1, y1 := from.X+ux*from.R, from.Y+uy*from.R
+		x2, y2 := to.X-ux*(to.R+2), to.Y-uy*(to.R+2)
+		// Bend each arrow to its right so opposite directions don't overlap.
+		mx, my := (x1+x2)/2-uy*d*0.12, (y1+y2)/2+ux*d*0.12
+		fmt.Fprintf(&b, `  <path d="M%.1f,%.1f Q%.1f,%.1f %.1f,%.1f" fill="none" stroke="#8a7e72" stroke-width="%.0f" opacity="0.6" marker-end="url(#arrow)"><title>%s</title></path>`+"\n",
+			x1, y1, mx, my, x2, y2, width, title)
+	}
+	for _, n := range nodes {
+		p := pos[n.Kana]
+		fill, text := "#ede8dc", "#2c2420"
+		if n.Forced > 0 && n.Forced == maxForced {
+			fill, text = "#c23a22", "#ffffff"
+		}
+		fmt.Fprintf(&b, `  <circle cx="%.1f" cy="%.1f" r="%.0f" fill="%s" stroke="#d8d0c4"><title>「%c」で終わる単語 %d / 始まる単語 %d</title></circle>`+"\n",
+			p.X, p.Y, p.R, fill, n.Kana, n.Forced, n.Started)
+		fmt.Fprintf(&b, `  <text x="%.1f" y="%.1f" font-size="15" font-weight="700" fill="%s" text-anchor="middle">%c</text>`+"\n",
+			p.X, p.Y+5, text, n.Kana)
+	}
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+// HandleHardestMomentSVG highlights the word that took longest to find.
+func (s *Server) HandleHardestMomentSVG(w http.ResponseWriter, r *http.Request) {
+	result, err := s.loadVisibleResult(r, r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeSVG(w, result, hardestMomentSVG(result))
+}
+
+func hardestMomentSVG(result *GameResult) string {
+	steps := chainSteps(result)
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="600" height="170" viewBox="0 0 600 170" font-family="sans-serif">
+  <rect width="600" height="170" fill="#faf7f0"/>
+  <text x="20" y="28" font-size="14" font-weight="700" fill="#2c2420">いちばん悩んだ瞬間</text>
+`)
+	i := hardestMoment(steps)
+	if i < 0 {
+		b.WriteString(`  <text x="300" y="100" font-size="14" fill="#8a7e72" text-anchor="middle">（記録がありません）</text>` + "\n</svg>")
+		return b.String()
+	}
+	st := steps[i]
+	prev := "ゲーム開始"
+	if i > 0 {
+		prev = "「" + steps[i-1].Word + "」"
+	}
+	fmt.Fprintf(&b, `  <text x="300" y="70" font-size="14" fill="#8a7e72" text-anchor="middle">%s のあと</text>
+  <text x="300" y="112" font-size="32" font-weight="700" fill="#a12e18" text-anchor="middle">「%s」</text>
+  <text x="300" y="148" font-size="14" fill="#2c2420" text-anchor="middle">%sさんが %d秒 考えました（%d語目・%s）</text>
+</svg>`,
+		svgEsc(prev), svgEsc(st.Word), svgEsc(st.Player),
+		int(st.Think.Round(time.Second)/time.Second), i+1, formatClock(st.At))
+	return b.String()
+}
+
+func writeSVG(w http.ResponseWriter, result *GameResult, svg string) {
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", resultImageCacheControl(result))
+	w.Write([]byte(svg))
+}
@@ -0,0 +1,109 @@
+package srv
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func vizTestResult() *GameResult {
+	start := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	at := func(sec int) string { return start.Add(time.Duration(sec) * time.Second).Format(time.RFC3339) }
+	return &GameResult{
+		RoomName: "viz",
+		Scores:   map[string]int{"alice": 2, "bob": 1, "carol": 0},
+		History: []WordEntry{
+			{Word: "しりとり", Player: "alice", Time: at(5)},
+			{Word: "りんご", Player: "bob", Time: at(9)},
+			{Word: "ごりら", Player: "alice", Time: at(40)},
+		},
+		Events: []GameEvent{{Seq: 1, Type: EventStart, Time: start}},
+	}
+}
+
+func TestChainStepsAndHardestMoment(t *testing.T) {
+	steps := chainSteps(vizTestResult())
+	want := []time.Duration{5 * time.Second, 4 * time.Second, 31 * time.Second}
+	for i, st := range steps {
+		if st.Think != want[i] {
+			t.Errorf("word %d: expected think time %v, got %v", i, want[i], st.Think)
+		}
+	}
+	if steps[2].At != 40*time.Second {
+		t.Errorf("expected last word at 40s, got %v", steps[2].At)
+	}
+	if i := hardestMoment(steps); i != 2 {
+		t.Errorf("expected the third word to be hardest, got %d", i)
+	}
+	if i := hardestMoment(chainSteps(&GameResult{History: []WordEntry{{Word: "しりとり"}}})); i != -1 {
+		t.Errorf("expected no hardest moment without timestamps, got %d", i)
+	}
+}
+
+func TestKanaTransitions(t *testing.T) {
+	nodes, links := kanaTransitions([]WordEntry{
+		{Word: "しりとり"}, {Word: "リンゴ"}, {Word: "ごりら"}, {Word: "らっぱー"},
+	})
+	forced := make(map[rune]int)
+	for _, n := range nodes {
+		forced[n.Kana] = n.Forced
+	}
+	if forced['り'] != 1 || forced['ご'] != 1 || forced['ぱ'] != 1 || forced['し'] != 0 {
+		t.Errorf("unexpected forced counts: %v", forced)
+	}
+	if len(links) != 4 || links[0].From != 'ご' || links[0].To != 'ら' {
+		t.Errorf("unexpected links: %+v", links)
+	}
+}
+
+func TestVisualizationEndpoints(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_viz.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	id, err := server.saveGameResult(vizTestResult())
+	if err != nil {
+		t.Fatalf("save result: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /results/{id}/timeline.svg", server.HandleTimelineSVG)
+	mux.HandleFunc("GET /results/{id}/kana.svg", server.HandleKanaGraphSVG)
+	mux.HandleFunc("GET /results/{id}/hardest.svg", server.HandleHardestMomentSVG)
+
+	for path, want := range map[string]string{
+		"timeline.svg": "carol（0）",
+		"kana.svg":     "り→ご ×1",
+		"hardest.svg":  "31秒",
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", "/results/"+id+"/"+path, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/svg+xml" {
+			t.Errorf("%s: expected SVG, got %d %q", path, rec.Code, rec.Header().Get("Content-Type"))
+			continue
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, want) {
+			t.Errorf("%s: expected %q in %s", path, want, body)
+		}
+		dec := xml.NewDecoder(strings.NewReader(body))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s: invalid XML: %v", path, err)
+				break
+			}
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/results/missing/kana.svg", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown result, got %d", rec.Code)
+	}
+}
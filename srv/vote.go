@@ -7,17 +7,18 @@ import (
 
 // PendingVote holds state for an in-progress genre vote.
 type PendingVote struct {
-	Word       string
-	Hiragana   string
-	Player     string
-	Challenger string
-	Votes      map[string]bool // player name -> accept (true) / reject (false)
-	Type       string          // "genre" or "challenge"
-	Reason     string
-	Resolved   bool
+	Word       string          `json:"word"`
+	Hiragana   string          `json:"hiragana"`
+	Player     string          `json:"player"`
+	Challenger string          `json:"challenger,omitempty"`
+	Votes      map[string]bool `json:"votes"` // player name -> accept (true) / reject (false)
+	Type       string          `json:"type"`  // "genre" or "challenge"
+	Reason     string          `json:"reason"`
+	Resolved   bool            `json:"resolved"`
 }
 
-// VoteResolution is the outcome of a vote.
+// VoteResolution is the outcome of a vote. Accepts and Rejects are the final
+// tally, with missing votes counted as rejections.
 type VoteResolution struct {
 	Type       string
 	Word       string
@@ -25,6 +26,8 @@ type VoteResolution struct {
 	Challenger string
 	Accepted   bool
 	Reverted   bool
+	Accepts    int
+	Rejects    int
 }
 
 // VoteInfo describes a new vote request.
@@ -71,6 +74,28 @@ func (vm *VoteManager) GetPending() *PendingVote {
 	return vm.pendingVote
 }
 
+// ExportPending returns a copy of the unresolved pending vote, or nil.
+func (vm *VoteManager) ExportPending() *PendingVote {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	if vm.pendingVote == nil || vm.pendingVote.Resolved {
+		return nil
+	}
+	pv := *vm.pendingVote
+	pv.Votes = make(map[string]bool, len(vm.pendingVote.Votes))
+	for k, v := range vm.pendingVote.Votes {
+		pv.Votes[k] = v
+	}
+	return &pv
+}
+
+// RestorePending installs a pending vote loaded from a snapshot.
+func (vm *VoteManager) RestorePending(pv *PendingVote) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	vm.pendingVote = pv
+}
+
 // Clear removes any pending vote.
 func (vm *VoteManager) Clear() {
 	vm.mu.Lock()
@@ -119,21 +144,32 @@ func (vm *VoteManager) StartChallengeVote(challengerName string, lastWord WordEn
 	return info, nil
 }
 
-// CastVote records a player's vote and returns resolution if all votes are in.
-func (vm *VoteManager) CastVote(playerName string, accept bool) (resolved bool, result VoteResolution) {
+// StartWordVote starts a vote on whether to accept a word a rule flagged.
+// The submitter's own vote counts as an accept.
+func (vm *VoteManager) StartWordVote(word, hiragana, playerName, reason string) error {
 	vm.mu.Lock()
 	defer vm.mu.Unlock()
 
-	if vm.pendingVote == nil || vm.pendingVote.Resolved {
-		return false, VoteResolution{}
+	if vm.pendingVote != nil && !vm.pendingVote.Resolved {
+		return fmt.Errorf("投票中です。投票が終わるまでお待ちください")
 	}
-
-	if !vm.playerExists(playerName) {
-		return false, VoteResolution{}
+	vm.pendingVote = &PendingVote{
+		Word:     word,
+		Hiragana: hiragana,
+		Player:   playerName,
+		Votes:    map[string]bool{playerName: true},
+		Type:     "genre",
+		Reason:   reason,
 	}
+	return nil
+}
 
-	// The challenged player cannot vote
-	if vm.pendingVote.Type == "challenge" && vm.pendingVote.Player == playerName {
+// CastVote records a player's vote and returns resolution if all votes are in.
+func (vm *VoteManager) CastVote(playerName string, accept bool) (resolved bool, result VoteResolution) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	if !vm.canVoteLocked(playerName) {
 		return false, VoteResolution{}
 	}
 
@@ -147,6 +183,26 @@ func (vm *VoteManager) CastVote(playerName string, accept bool) (resolved bool,
 	return vm.resolveVoteLocked()
 }
 
+// CanVote reports whether a vote from playerName would be counted.
+func (vm *VoteManager) CanVote(playerName string) bool {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	return vm.canVoteLocked(playerName)
+}
+
+// canVoteLocked checks there is an open vote the player may take part in.
+// Caller must hold vm.mu.
+func (vm *VoteManager) canVoteLocked(playerName string) bool {
+	if vm.pendingVote == nil || vm.pendingVote.Resolved {
+		return false
+	}
+	if !vm.playerExists(playerName) {
+		return false
+	}
+	// The challenged player cannot vote
+	return vm.pendingVote.Type != "challenge" || vm.pendingVote.Player != playerName
+}
+
 // ForceResolveVote resolves the vote by timeout (majority wins, tie = reject).
 func (vm *VoteManager) ForceResolveVote() (resolved bool, result VoteResolution) {
 	vm.mu.Lock()
@@ -223,6 +279,8 @@ func (vm *VoteManager) resolveVoteLocked() (resolved bool, result VoteResolution
 		Player:     vm.pendingVote.Player,
 		Challenger: vm.pendingVote.Challenger,
 		Accepted:   accepted,
+		Accepts:    acceptCount,
+		Rejects:    rejectCount,
 	}
 
 	// For genre votes
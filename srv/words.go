@@ -0,0 +1,91 @@
+package srv
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// Start modes for RoomSettings.StartMode: how the first word of a game is
+// decided.
+const (
+	StartOwner  = "owner"  // the first player picks any word (default)
+	StartRandom = "random" // a random word from startWords
+	StartFixed  = "fixed"  // RoomSettings.StartWord, or defaultStartWord if unusable
+	StartKana   = "kana"   // a random kana the first word must begin with
+)
+
+// defaultStartWord opens fixed-start games that don't name a usable word.
+const defaultStartWord = "しりとり"
+
+// startKana are the kana a game may open on, and a daily puzzle may target.
+const startKana = "あいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほまみむめもやゆよらりるれろわ"
+
+// startWords is a small dictionary of everyday nouns used to open games.
+// Every entry is hiragana and ends in a kana other than ん.
+var startWords = []string{
+	"しりとり", "りんご", "ごりら", "らっぱ", "ぱいなっぷる", "あさがお",
+	"いちご", "うさぎ", "えんぴつ", "おにぎり", "きつね", "くるま",
+	"けいと", "こあら", "さくら", "しまうま", "すいか", "せみ",
+	"そら", "たぬき", "ちくわ", "つくえ", "てがみ", "とけい",
+	"なす", "にわとり", "ぬいぐるみ", "ねこ", "のり", "はさみ",
+	"ひまわり", "へちま", "ほたる", "まくら", "むぎちゃ", "めだか",
+	"もみじ", "ゆきだるま", "らくだ", "りす", "るびー", "ろうそく",
+	"わかめ", "いるか", "かえる", "かめ", "きのこ", "くじら",
+	"こま", "さかな", "たまご", "とまと", "はなび", "ひこうき",
+	"ふね", "ぶどう", "ほし", "まつり", "みずうみ", "めがね",
+	"もも", "やま", "ゆかた", "ようかい", "あめ", "いす",
+	"うみ", "えだまめ", "おちゃ", "かさ", "くつした", "こおり",
+	"さる", "しお", "すずめ", "たこ", "ちず", "つばめ",
+	"てつぼう", "とり", "なつ", "にじ", "ねずみ", "のはら",
+	"はと", "ひつじ", "ふくろう", "へび", "ほうき", "まど",
+	"みそしる", "むし", "もぐら", "やさい", "ゆび", "よぞら",
+	"れいぞうこ", "ろけっと",
+}
+
+// playableFrom reports whether the rules in s allow a word to begin with r.
+func playableFrom(r rune, s RoomSettings) bool {
+	if s.NoDakuten && (IsDakuten(r) || IsHandakuten(r)) {
+		return false
+	}
+	if len(s.AllowedRows) > 0 && !slices.Contains(s.AllowedRows, GetKanaRow(r)) {
+		return false
+	}
+	return true
+}
+
+// validStartWord reports whether a normalized word can open a game: kana
+// that doesn't end in ん.
+func validStartWord(word string) bool {
+	if !isJapanese(word) {
+		return false
+	}
+	return getLastChar(toHiragana(word)) != 'ん'
+}
+
+// randomStartWord picks an opening word the rules in s can continue from.
+func randomStartWord(s RoomSettings) string {
+	var words []string
+	for _, w := range startWords {
+		if playableFrom(linkKana(w, s.ChainMode), s) {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		words = startWords
+	}
+	return words[rand.IntN(len(words))]
+}
+
+// randomStartKana picks an opening kana the rules in s allow.
+func randomStartKana(s RoomSettings) rune {
+	var kana []rune
+	for _, r := range startKana {
+		if playableFrom(r, s) {
+			kana = append(kana, r)
+		}
+	}
+	if len(kana) == 0 {
+		kana = []rune(startKana)
+	}
+	return kana[rand.IntN(len(kana))]
+}
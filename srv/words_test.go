@@ -0,0 +1,111 @@
+package srv
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStartWordsArePlayable(t *testing.T) {
+	for _, w := range startWords {
+		if !validStartWord(w) || toHiragana(w) != w {
+			t.Errorf("start word %q must be hiragana not ending in ん", w)
+		}
+	}
+}
+
+func TestValidateStartWord(t *testing.T) {
+	for word, ok := range map[string]bool{
+		"":      true, // falls back to defaultStartWord
+		"リンゴ":   true,
+		"ｼﾘﾄﾘ":  true,
+		"みかん":   false,
+		"apple": false,
+		"しり とり": false,
+	} {
+		err := validateSettings(RoomSettings{StartMode: StartFixed, StartWord: word})
+		if (err == nil) != ok {
+			t.Errorf("%q: got %v, want ok=%v", word, err, ok)
+		}
+	}
+	if err := validateSettings(RoomSettings{StartWord: "みかん"}); err != nil {
+		t.Errorf("expected the start word to be ignored outside fixed mode, got %v", err)
+	}
+}
+
+func TestEngineOpen(t *testing.T) {
+	open := func(s RoomSettings) *GameEngine {
+		ge := NewGameEngine(s, []string{"alice", "bob"}, nil)
+		ge.Open()
+		return ge
+	}
+
+	if ge := open(RoomSettings{}); ge.CurrentWord != "" || ge.StartWord != "" {
+		t.Errorf("expected owner choice to open on nothing, got %q", ge.CurrentWord)
+	}
+
+	ge := open(RoomSettings{StartMode: StartFixed, StartWord: "しりとり"})
+	if ge.CurrentWord != "しりとり" {
+		t.Fatalf("expected a fixed opening word, got %q", ge.CurrentWord)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("らっぱ", "alice", false); res != ValidateRejected {
+		t.Errorf("expected the first word to continue from the opening word, got %v", res)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("りんご", "alice", false); res != ValidateOK {
+		t.Errorf("expected りんご to follow しりとり, got %v", res)
+	}
+	if res, _ := ge.ValidateAndSubmitWord("ごりら", "bob", false); res != ValidateOK {
+		t.Fatalf("expected ごりら to be accepted, got %v", res)
+	}
+	ge.RevertWord("ごりら", "bob")
+	ge.RevertWord("りんご", "alice")
+	if ge.CurrentWord != "しりとり" || len(ge.History) != 0 {
+		t.Errorf("expected reverting every word to return to the opening word, got %q", ge.CurrentWord)
+	}
+
+	for _, word := range []string{"", "apple", "みかん"} {
+		if ge := open(RoomSettings{StartMode: StartFixed, StartWord: word}); ge.CurrentWord != defaultStartWord {
+			t.Errorf("fixed %q: expected fallback to %s, got %q", word, defaultStartWord, ge.CurrentWord)
+		}
+	}
+
+	rows := []string{"あ行", "か行"}
+	for range 50 {
+		ge := open(RoomSettings{StartMode: StartRandom, AllowedRows: rows})
+		if !slices.Contains(startWords, ge.CurrentWord) || !ge.UsedWords[ge.CurrentWord] {
+			t.Fatalf("expected a used dictionary word, got %q", ge.CurrentWord)
+		}
+		if row := GetKanaRow(getLastChar(ge.CurrentWord)); !slices.Contains(rows, row) {
+			t.Fatalf("random word %q can't be continued within %v", ge.CurrentWord, rows)
+		}
+
+		ge = open(RoomSettings{StartMode: StartKana, NoDakuten: true})
+		r := []rune(ge.CurrentWord)
+		if len(r) != 1 || IsDakuten(r[0]) || ge.UsedWords[ge.CurrentWord] {
+			t.Fatalf("expected an unused plain start kana, got %q", ge.CurrentWord)
+		}
+	}
+}
+
+func TestGameStartedReportsFirstWord(t *testing.T) {
+	server, err := New(filepath.Join(t.TempDir(), "test_words.sqlite3"), "test-hostname")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	room := server.Rooms.CreateRoom("wd01", RoomSettings{Name: "start", StartMode: StartFixed, StartWord: "シリトリ"})
+	room.Owner = "alice"
+	server.setUpRoom(room)
+	alice := &Player{Name: "alice", Send: make(chan []byte, 256)}
+	room.AddPlayer(alice)
+	server.handleStartGame(room)
+
+	var msg map[string]any
+	json.Unmarshal(<-alice.Send, &msg)
+	if msg["type"] != "game_started" || msg["firstWord"] != "シリトリ" || msg["currentWord"] != "シリトリ" {
+		t.Errorf("expected game_started with firstWord, got %v", msg)
+	}
+	if ev := room.Engine.EventLog()[0]; ev.Type != EventStart || ev.Word != "シリトリ" {
+		t.Errorf("expected the start event to record the opening word, got %+v", ev)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -38,6 +39,10 @@ type WSMessage struct {
 	Accept   *bool         `json:"accept,omitempty"`    // for vote messages
 	Reason   string        `json:"reason,omitempty"`    // for challenge
 	Rebuttal string        `json:"rebuttal,omitempty"` // for challenged player's rebuttal
+	Password string        `json:"password,omitempty"` // for joining password-protected rooms
+	Prompt   *int          `json:"prompt,omitempty"`   // for answers: words played when the player saw the prompt
+	Item     string        `json:"item,omitempty"`     // for use_item: see ItemPass etc.
+	Kana     string        `json:"kana,omitempty"`     // for use_item: the kana picked for ItemForce
 
 	// Response fields
 	Success bool       `json:"success,omitempty"`
@@ -72,6 +77,7 @@ type WSConn struct {
 	currentRoom   *Room
 	currentPlayer *Player
 	rateLimiter   *ConnectionRateLimiter
+	remoteIP      string
 }
 
 // sendDirect writes a message directly to the WebSocket connection.
@@ -91,6 +97,7 @@ func (wsc *WSConn) sendToPlayer(v any) {
 	case wsc.currentPlayer.Send <- data:
 	default:
 		// drop if channel full
+		metrics.broadcastsDropped.Inc("direct")
 	}
 }
 
@@ -128,6 +135,10 @@ func (wsc *WSConn) leaveCurrentRoom() {
 		"players": wsc.currentRoom.PlayerNames(),
 	}))
 
+	if remaining > 0 && wsc.currentRoom.RematchReady() {
+		wsc.server.handleStartGame(wsc.currentRoom)
+	}
+
 	if remaining == 0 {
 		wsc.currentRoom.StopTimer()
 		now := time.Now()
@@ -163,18 +174,54 @@ func (wsc *WSConn) handleCreateRoom(msg WSMessage) {
 		wsc.sendErr("名前とルーム設定が必要です")
 		return
 	}
-	// Check if this name is already in a room (from another connection)
-	if existingRoomID := wsc.server.Rooms.PlayerRoomID(msg.Name); existingRoomID != "" {
-		// Only allow if this is the same connection & same player name (re-creating)
-		if wsc.playerName != msg.Name || wsc.currentRoom == nil || wsc.currentRoom.ID != existingRoomID {
-			wsc.sendErr(fmt.Sprintf("「%s」は既に別のルームに参加しています", msg.Name))
-			return
-		}
+	if !wsc.canCreateRoom(msg.Name) {
+		return
+	}
+	if err := validateSettings(*msg.Settings); err != nil {
+		wsc.sendErr(err.Error())
+		return
 	}
 	// Leave current room first if in one
 	wsc.leaveCurrentRoom()
 	wsc.playerName = msg.Name
 	room, player := wsc.server.handleCreateRoom(wsc.conn, wsc.playerName, msg.Settings)
+	wsc.enterRoom(room, player)
+}
+
+func (wsc *WSConn) handleStartDaily(msg WSMessage) {
+	if msg.Name == "" {
+		wsc.sendErr("名前が必要です")
+		return
+	}
+	if !wsc.canCreateRoom(msg.Name) {
+		return
+	}
+	wsc.leaveCurrentRoom()
+	wsc.playerName = msg.Name
+	room, player := wsc.server.handleCreateDailyRoom(wsc.conn, wsc.playerName)
+	wsc.enterRoom(room, player)
+}
+
+// canCreateRoom reports whether name may create a new room, sending the
+// reason to the client if not.
+func (wsc *WSConn) canCreateRoom(name string) bool {
+	if wsc.server.Rooms.Draining() {
+		wsc.sendErr("サーバーがまもなく停止するため、新しいルームは作成できません")
+		return false
+	}
+	// Check if this name is already in a room (from another connection)
+	if existingRoomID := wsc.server.Rooms.PlayerRoomID(name); existingRoomID != "" {
+		// Only allow if this is the same connection & same player name (re-creating)
+		if wsc.playerName != name || wsc.currentRoom == nil || wsc.currentRoom.ID != existingRoomID {
+			wsc.sendErr(fmt.Sprintf("「%s」は既に別のルームに参加しています", name))
+			return false
+		}
+	}
+	return true
+}
+
+// enterRoom makes room the connection's current room after creating it.
+func (wsc *WSConn) enterRoom(room *Room, player *Player) {
 	wsc.currentRoom = room
 	wsc.currentPlayer = player
 	wsc.server.Rooms.TrackPlayer(wsc.playerName, wsc.currentRoom.ID)
@@ -197,7 +244,7 @@ func (wsc *WSConn) handleJoin(msg WSMessage) {
 	// Leave current room first if in one
 	wsc.leaveCurrentRoom()
 	wsc.playerName = msg.Name
-	room, player, err := wsc.server.handleJoinRoom(wsc.conn, wsc.playerName, msg.RoomID)
+	room, player, err := wsc.server.handleJoinRoom(wsc.conn, wsc.playerName, msg.RoomID, msg.Password, wsc.remoteIP)
 	if err != nil {
 		wsc.sendErr(err.Error())
 		return
@@ -221,6 +268,10 @@ func (wsc *WSConn) handleStartGame(msg WSMessage) {
 		wsc.sendErr("ゲームを開始できるのはルーム作成者のみです")
 		return
 	}
+	if err := wsc.currentRoom.CanStartGame(); err != nil {
+		wsc.sendErr(err.Error())
+		return
+	}
 	if msg.Settings != nil {
 		if err := wsc.currentRoom.UpdateSettings(*msg.Settings); err != nil {
 			wsc.sendErr(err.Error())
@@ -230,6 +281,7 @@ func (wsc *WSConn) handleStartGame(msg WSMessage) {
 		wsc.currentRoom.Broadcast(mustMarshal(map[string]any{
 			"type":     "settings_updated",
 			"settings": wsc.currentRoom.Settings,
+			"rules":    ruleInfos(wsc.currentRoom.Settings),
 		}))
 	}
 	wsc.server.handleStartGame(wsc.currentRoom)
@@ -240,7 +292,19 @@ func (wsc *WSConn) handleAnswer(msg WSMessage) {
 		wsc.sendErr("ルームに参加していません")
 		return
 	}
-	wsc.server.handleAnswer(wsc.currentRoom, wsc.playerName, msg.Word)
+	prompt := -1
+	if msg.Prompt != nil {
+		prompt = *msg.Prompt
+	}
+	wsc.server.submitAnswer(wsc.currentRoom, wsc.playerName, msg.Word, prompt)
+}
+
+func (wsc *WSConn) handleUseItem(msg WSMessage) {
+	if wsc.currentRoom == nil || wsc.playerName == "" {
+		wsc.sendErr("ルームに参加していません")
+		return
+	}
+	wsc.server.handleUseItem(wsc.currentRoom, wsc.playerName, msg.Item, msg.Kana)
 }
 
 func (wsc *WSConn) handleVote(msg WSMessage) {
@@ -283,6 +347,14 @@ func (wsc *WSConn) handleWithdrawChallenge(msg WSMessage) {
 	wsc.server.handleWithdrawChallenge(wsc.currentRoom, wsc.playerName)
 }
 
+func (wsc *WSConn) handleRematch(msg WSMessage) {
+	if wsc.currentRoom == nil || wsc.playerName == "" {
+		wsc.sendErr("ルームに参加していません")
+		return
+	}
+	wsc.server.handleRematch(wsc.currentRoom, wsc.playerName)
+}
+
 func (wsc *WSConn) handlePing(msg WSMessage) {
 	wsc.sendMsg(map[string]any{
 		"type": "pong",
@@ -308,6 +380,7 @@ func (wsc *WSConn) readLoop() {
 		// Rate limit check
 		allowed, shouldDisconnect := wsc.rateLimiter.Allow(msg.Type)
 		if !allowed {
+			metrics.rateLimitRejected.Inc(messageTypeLabel(msg.Type), strconv.FormatBool(shouldDisconnect))
 			if shouldDisconnect {
 				slog.Warn("rate limit exceeded, disconnecting", "player", wsc.playerName, "type", msg.Type)
 				wsc.sendErr("レート制限を超過しました。接続を切断します。")
@@ -316,6 +389,7 @@ func (wsc *WSConn) readLoop() {
 			wsc.sendErr("操作が速すぎます。少し待ってからやり直してください。")
 			continue
 		}
+		metrics.wsMessages.Inc(messageTypeLabel(msg.Type))
 
 		switch msg.Type {
 		case "get_rooms":
@@ -324,6 +398,8 @@ func (wsc *WSConn) readLoop() {
 			wsc.handleGetGenres(msg)
 		case "create_room":
 			wsc.handleCreateRoom(msg)
+		case "start_daily":
+			wsc.handleStartDaily(msg)
 		case "join":
 			wsc.handleJoin(msg)
 		case "leave_room":
@@ -332,6 +408,8 @@ func (wsc *WSConn) readLoop() {
 			wsc.handleStartGame(msg)
 		case "answer":
 			wsc.handleAnswer(msg)
+		case "use_item":
+			wsc.handleUseItem(msg)
 		case "vote":
 			wsc.handleVote(msg)
 		case "challenge":
@@ -340,6 +418,8 @@ func (wsc *WSConn) readLoop() {
 			wsc.handleRebuttal(msg)
 		case "withdraw_challenge":
 			wsc.handleWithdrawChallenge(msg)
+		case "rematch":
+			wsc.handleRematch(msg)
 		case "ping":
 			wsc.handlePing(msg)
 		default:
@@ -366,6 +446,7 @@ func (s *Server) HandleWS(w http.ResponseWriter, r *http.Request) {
 		server:      s,
 		conn:        conn,
 		rateLimiter: NewConnectionRateLimiter(),
+		remoteIP:    s.clientIP(r),
 	}
 	wsc.readLoop()
 }
@@ -400,10 +481,9 @@ func writePump(conn *websocket.Conn, p *Player) {
 	}
 }
 
-func (s *Server) handleCreateRoom(conn *websocket.Conn, name string, settings *RoomSettings) (*Room, *Player) {
-	roomID := generateRoomID()
-	room := s.Rooms.CreateRoom(roomID, *settings)
-	room.Owner = name
+// setUpRoom wires the server callbacks, vote manager and timer into a room.
+// Used for newly created rooms and rooms restored from a snapshot.
+func (s *Server) setUpRoom(room *Room) {
 	room.OnGameOver = s.makeGameOverCallback()
 
 	// Set up vote manager
@@ -436,9 +516,13 @@ func (s *Server) handleCreateRoom(conn *websocket.Conn, name string, settings *R
 				return
 			}
 			room.Status = "finished"
+			if room.Fuse != nil {
+				room.Fuse.Stop()
+			}
 			loser := ""
 			if room.Engine != nil {
 				loser = room.Engine.CurrentTurn()
+				room.Engine.RecordEvent(GameEvent{Type: EventTimeout, Player: loser})
 			}
 			var history []WordEntry
 			if room.Engine != nil {
@@ -452,14 +536,50 @@ func (s *Server) handleCreateRoom(conn *websocket.Conn, name string, settings *R
 				"history": history,
 				"lives":   room.getLivesLocked(),
 			}
+			// Nobody holds the turn in a race; the top scorer wins.
+			if room.Settings.Race {
+				gameOverMsg["winner"] = topScorer(room.getScoresLocked())
+			}
+			room.mu.Unlock()
+
 			if room.OnGameOver != nil {
 				gameOverMsg = room.OnGameOver(room, gameOverMsg)
 			}
-			room.broadcastLocked(mustMarshal(gameOverMsg))
-			room.mu.Unlock()
+			room.Broadcast(mustMarshal(gameOverMsg))
 		},
 	)
 
+	// Bomb mode's fuse is never reported to clients, only its explosions.
+	room.Fuse = NewFuseTimer(fuseMin, fuseMax, func() { s.explodeBomb(room) })
+}
+
+// explodeBomb costs the turn holder a life when the fuse runs out.
+func (s *Server) explodeBomb(room *Room) {
+	room.mu.Lock()
+	playing := room.Status == "playing" && room.Engine != nil
+	room.mu.Unlock()
+	if !playing {
+		room.Fuse.Stop()
+		return
+	}
+	holder := room.Engine.Explode()
+	if holder == "" {
+		return
+	}
+	room.Broadcast(mustMarshal(map[string]any{
+		"type":        "bomb_exploded",
+		"player":      holder,
+		"currentTurn": room.Engine.CurrentTurn(),
+	}))
+	s.broadcastPenalty(room, holder, bombReason)
+}
+
+func (s *Server) handleCreateRoom(conn *websocket.Conn, name string, settings *RoomSettings) (*Room, *Player) {
+	roomID := generateRoomID()
+	room := s.Rooms.CreateRoom(roomID, *settings)
+	room.Owner = name
+	s.setUpRoom(room)
+
 	player := &Player{
 		Name: name,
 		Conn: conn,
@@ -482,11 +602,23 @@ func (s *Server) handleCreateRoom(conn *websocket.Conn, name string, settings *R
 	return room, player
 }
 
-func (s *Server) handleJoinRoom(conn *websocket.Conn, name, roomID string) (*Room, *Player, error) {
+func (s *Server) handleJoinRoom(conn *websocket.Conn, name, roomID, password, remoteIP string) (*Room, *Player, error) {
+	ipKey := "ip:" + remoteIP
+	if s.JoinFailures.Blocked(ipKey) {
+		return nil, nil, fmt.Errorf("参加の失敗が多すぎます。しばらく待ってからやり直してください")
+	}
+
 	room := s.Rooms.GetRoom(roomID)
 	if room == nil {
+		// Count unknown IDs too so room IDs can't be enumerated.
+		s.JoinFailures.RecordFailure(ipKey)
 		return nil, nil, fmt.Errorf("ルームが見つかりません: %s", roomID)
 	}
+	if !room.CheckPassword(password) {
+		s.JoinFailures.RecordFailure(ipKey)
+		slog.Warn("wrong room password", "roomId", roomID, "ip", remoteIP)
+		return nil, nil, fmt.Errorf("パスワードが違います")
+	}
 
 	room.mu.Lock()
 	if _, exists := room.Players[name]; exists {
@@ -506,6 +638,7 @@ func (s *Server) handleJoinRoom(conn *websocket.Conn, name, roomID string) (*Roo
 		Send: make(chan []byte, 256),
 	}
 	room.AddPlayer(player)
+	room.ResumeTimer()
 
 	slog.Info("player joined", "roomId", roomID, "player", name)
 
@@ -562,30 +695,85 @@ func (s *Server) handleStartGame(room *Room) {
 	}
 
 	currentTurn := ""
+	currentWord := ""
 	var turnOrder []string
 	var lives map[string]int
+	var rules []RuleInfo
 	maxLives := defaultMaxLives
 	if room.Engine != nil {
 		currentTurn = room.Engine.CurrentTurn()
-		_, _, turnOrder, _ = room.Engine.Snapshot()
+		rules = room.Engine.RuleInfos()
+		_, currentWord, turnOrder, _ = room.Engine.Snapshot()
 		lives = room.Engine.GetLives()
 		maxLives = room.Engine.MaxLives()
 	}
 
-	room.Broadcast(mustMarshal(map[string]any{
+	started := map[string]any{
 		"type":        "game_started",
-		"currentWord": "",
+		"currentWord": currentWord,
+		"firstWord":   currentWord,
 		"history":     []WordEntry{},
 		"timeLimit":   room.Settings.TimeLimit,
 		"currentTurn": currentTurn,
 		"turnOrder":   turnOrder,
 		"lives":       lives,
 		"maxLives":    maxLives,
+		"rules":       rules,
+	}
+	if room.Match != nil {
+		started["match"] = room.Match.Summary()
+	}
+	if room.Daily != nil {
+		started["daily"] = room.Daily
+	}
+	if room.Settings.Ladder && room.Engine != nil {
+		started["ladderMin"] = room.Engine.MinLength()
+	}
+	if room.Engine != nil {
+		started["genre"] = room.Engine.ActiveGenre()
+		started["items"] = room.Engine.GetItems()
+	}
+	room.Broadcast(mustMarshal(started))
+}
+
+func (s *Server) handleRematch(room *Room, playerName string) {
+	ready, allReady, err := room.SetRematchReady(playerName)
+	if err != nil {
+		room.mu.Lock()
+		if p, ok := room.Players[playerName]; ok {
+			select {
+			case p.Send <- mustMarshal(map[string]any{
+				"type":    "error",
+				"message": err.Error(),
+			}):
+			default:
+				metrics.broadcastsDropped.Inc("direct")
+			}
+		}
+		room.mu.Unlock()
+		return
+	}
+
+	room.Broadcast(mustMarshal(map[string]any{
+		"type":         "rematch_update",
+		"player":       playerName,
+		"ready":        ready,
+		"totalPlayers": len(room.PlayerNames()),
 	}))
+
+	if allReady {
+		s.handleStartGame(room)
+	}
 }
 
 func (s *Server) handleAnswer(room *Room, playerName, word string) {
-	result, msg := room.ValidateAndSubmitWord(word, playerName)
+	s.submitAnswer(room, playerName, word, -1)
+}
+
+// submitAnswer handles an answer to a given prompt (see Room.SubmitAnswer).
+func (s *Server) submitAnswer(room *Room, playerName, word string, prompt int) {
+	word = normalizeWord(word)
+	result, msg := room.SubmitAnswer(word, playerName, prompt)
 
 	switch result {
 	case ValidateRejected:
@@ -599,6 +787,7 @@ func (s *Server) handleAnswer(room *Room, playerName, word string) {
 				"message": msg,
 			}):
 			default:
+				metrics.broadcastsDropped.Inc("direct")
 			}
 		}
 		room.mu.Unlock()
@@ -620,7 +809,7 @@ func (s *Server) handleAnswer(room *Room, playerName, word string) {
 			"voteType":     voteType,
 			"word":         word,
 			"player":       playerName,
-			"genre":        room.Settings.Genre,
+			"genre":        room.Engine.ActiveGenre(),
 			"message":      msg,
 			"reason":       voteReason,
 			"voteCount":    voteCount,
@@ -641,52 +830,106 @@ func (s *Server) handleAnswer(room *Room, playerName, word string) {
 
 	case ValidatePenalty:
 		// Word NOT accepted, but player loses a life
-		livesLeft := 0
-		if room.Engine != nil {
-			livesLeft = room.Engine.GetPlayerLives(playerName)
-		}
+		s.broadcastPenalty(room, playerName, msg)
+	}
+}
+
+// broadcastPenalty tells the room a player lost a life, ending the game if
+// that leaves too few players standing.
+func (s *Server) broadcastPenalty(room *Room, playerName, msg string) {
+	livesLeft := 0
+	if room.Engine != nil {
+		livesLeft = room.Engine.GetPlayerLives(playerName)
+	}
+	room.mu.Lock()
+	totalPlayers := len(room.Players)
+	room.mu.Unlock()
+	eliminated, gameOver, lastSurvivor := room.Engine.CheckElimination(playerName, totalPlayers)
+	lives := room.Engine.GetLives()
+	scores := room.Engine.GetScores()
+	history, _, _, _ := room.Engine.Snapshot()
+
+	penalty := map[string]any{
+		"type":       "penalty",
+		"player":     playerName,
+		"reason":     msg,
+		"lives":      livesLeft,
+		"eliminated": eliminated,
+		"allLives":   lives,
+	}
+	if room.Settings.Ladder {
+		penalty["ladderMin"] = room.Engine.MinLength()
+	}
+	room.Broadcast(mustMarshal(penalty))
+
+	if gameOver {
 		room.mu.Lock()
-		totalPlayers := len(room.Players)
+		room.Status = "finished"
 		room.mu.Unlock()
-		eliminated, gameOver, lastSurvivor := room.Engine.CheckElimination(playerName, totalPlayers)
-		lives := room.Engine.GetLives()
-		scores := room.Engine.GetScores()
-		history, _, _, _ := room.Engine.Snapshot()
-
-		room.Broadcast(mustMarshal(map[string]any{
-			"type":       "penalty",
-			"player":     playerName,
-			"reason":     msg,
-			"lives":      livesLeft,
-			"eliminated": eliminated,
-			"allLives":   lives,
-		}))
+		room.Votes.Clear()
+		room.StopTimer()
 
-		if gameOver {
-			room.mu.Lock()
-			room.Status = "finished"
-			room.mu.Unlock()
-			room.Votes.Clear()
-			room.StopTimer()
+		reason := "ゲーム終了"
+		if lastSurvivor != "" {
+			reason = fmt.Sprintf("%sさんの勝利！", lastSurvivor)
+		}
+		gameOverMsg := map[string]any{
+			"type":    "game_over",
+			"reason":  reason,
+			"winner":  lastSurvivor,
+			"loser":   playerName,
+			"scores":  scores,
+			"history": history,
+			"lives":   lives,
+		}
+		if room.OnGameOver != nil {
+			gameOverMsg = room.OnGameOver(room, gameOverMsg)
+		}
+		room.Broadcast(mustMarshal(gameOverMsg))
+	}
+}
 
-			reason := "ゲーム終了"
-			if lastSurvivor != "" {
-				reason = fmt.Sprintf("%sさんの勝利！", lastSurvivor)
-			}
-			gameOverMsg := map[string]any{
-				"type":    "game_over",
-				"reason":  reason,
-				"winner":  lastSurvivor,
-				"scores":  scores,
-				"history": history,
-				"lives":   lives,
-			}
-			if room.OnGameOver != nil {
-				gameOverMsg = room.OnGameOver(room, gameOverMsg)
+func (s *Server) handleUseItem(room *Room, playerName, item, kana string) {
+	if err := room.UseItem(playerName, item, kana); err != nil {
+		room.mu.Lock()
+		if p, exists := room.Players[playerName]; exists {
+			select {
+			case p.Send <- mustMarshal(map[string]any{
+				"type":    "item_rejected",
+				"item":    item,
+				"message": err.Error(),
+			}):
+			default:
+				metrics.broadcastsDropped.Inc("direct")
 			}
-			room.Broadcast(mustMarshal(gameOverMsg))
 		}
+		room.mu.Unlock()
+		return
 	}
+
+	_, _, turnOrder, _ := room.Engine.Snapshot()
+	used := map[string]any{
+		"type":        "item_used",
+		"player":      playerName,
+		"item":        item,
+		"label":       itemLabel(item),
+		"items":       room.Engine.GetItems(),
+		"currentTurn": room.Engine.CurrentTurn(),
+		"turnOrder":   turnOrder,
+	}
+	// A pass hands a queued force on to the next player.
+	if forced := room.Engine.Forced(); forced != "" {
+		used["forcedKana"] = forced
+	}
+	switch item {
+	case ItemForce:
+		used["kana"] = toHiragana(normalizeWord(kana))
+	case ItemTime:
+		if room.Timer != nil {
+			used["timeLeft"] = room.Timer.TimeLeft()
+		}
+	}
+	room.Broadcast(mustMarshal(used))
 }
 
 func (s *Server) handleVote(room *Room, playerName string, accept bool) {
@@ -718,6 +961,8 @@ func (s *Server) handleRebuttal(room *Room, playerName, rebuttal string) {
 		return
 	}
 
+	room.RecordEvent(GameEvent{Type: EventRebuttal, Actor: playerName, Player: playerName, Word: pv.Word, Text: rebuttal})
+
 	// Broadcast the rebuttal to all players
 	room.Broadcast(mustMarshal(map[string]any{
 		"type":     "rebuttal",
@@ -736,6 +981,7 @@ func (s *Server) handleWithdrawChallenge(room *Room, playerName string) {
 				"message": "指摘を取り下げることができません",
 			}):
 			default:
+				metrics.broadcastsDropped.Inc("direct")
 			}
 		}
 		room.mu.Unlock()
@@ -761,6 +1007,7 @@ func (s *Server) handleChallenge(room *Room, playerName string) {
 				"message": err.Error(),
 			}):
 			default:
+				metrics.broadcastsDropped.Inc("direct")
 			}
 		}
 		room.mu.Unlock()
@@ -854,7 +1101,7 @@ func (s *Server) broadcastVoteResult(room *Room, result VoteResolution) {
 		eliminated, gameOver, lastSurvivor = room.Engine.CheckElimination(result.Player, totalPlayers)
 	}
 
-	room.Broadcast(mustMarshal(map[string]any{
+	reverted := map[string]any{
 		"type":        "vote_result",
 		"voteType":    result.Type,
 		"word":        result.Word,
@@ -871,7 +1118,17 @@ func (s *Server) broadcastVoteResult(room *Room, result VoteResolution) {
 		"penaltyLives":  penaltyLivesLeft,
 		"eliminated":    eliminated,
 		"message":     fmt.Sprintf("投票により「%s」は却下されました。%sさんはライフ-1、もう一度入力してください", result.Word, result.Player),
-	}))
+	}
+	if room.Settings.Ladder && room.Engine != nil {
+		reverted["ladderMin"] = room.Engine.MinLength()
+	}
+	if room.Engine != nil {
+		reverted["genre"] = room.Engine.ActiveGenre()
+		if forced := room.Engine.Forced(); forced != "" {
+			reverted["forcedKana"] = forced
+		}
+	}
+	room.Broadcast(mustMarshal(reverted))
 
 	if gameOver {
 		room.mu.Lock()
@@ -888,6 +1145,7 @@ func (s *Server) broadcastVoteResult(room *Room, result VoteResolution) {
 			"type":    "game_over",
 			"reason":  reason,
 			"winner":  lastSurvivor,
+			"loser":   result.Player,
 			"scores":  scores,
 			"history": history,
 			"lives":   lives,
@@ -911,7 +1169,7 @@ func (s *Server) broadcastWordAccepted(room *Room, word, playerName string) {
 		history, _, _, _ = room.Engine.Snapshot()
 	}
 
-	room.Broadcast(mustMarshal(map[string]any{
+	accepted := map[string]any{
 		"type":        "word_accepted",
 		"word":        word,
 		"player":      playerName,
@@ -920,5 +1178,29 @@ func (s *Server) broadcastWordAccepted(room *Room, word, playerName string) {
 		"history":     history,
 		"currentTurn": nextTurn,
 		"lives":       lives,
-	}))
+	}
+	if room.Settings.Ladder && room.Engine != nil {
+		accepted["ladderMin"] = room.Engine.MinLength()
+	}
+	if n := len(history); room.Settings.Race && n > 0 {
+		accepted["responseMs"] = history[n-1].ResponseMs
+	}
+	genre := ""
+	if room.Engine != nil {
+		genre = room.Engine.ActiveGenre()
+		accepted["genre"] = genre
+		if forced := room.Engine.Forced(); forced != "" {
+			accepted["forcedKana"] = forced
+		}
+	}
+	room.Broadcast(mustMarshal(accepted))
+	// The word played under the previous genre if it completed a rotation.
+	if n := len(history); n > 0 && history[n-1].Genre != "" && history[n-1].Genre != genre {
+		room.Broadcast(mustMarshal(map[string]any{
+			"type":     "genre_changed",
+			"genre":    genre,
+			"previous": history[n-1].Genre,
+		}))
+	}
+	s.checkDailyCleared(room, word, playerName)
 }